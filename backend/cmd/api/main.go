@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -9,11 +11,13 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
 	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
 	handler "github.com/user/go-ecommerce/internal/handler/http"
 	"github.com/user/go-ecommerce/internal/handler/http/middleware"
 	"github.com/user/go-ecommerce/internal/infrastructure"
 	"github.com/user/go-ecommerce/internal/repository"
 	"github.com/user/go-ecommerce/internal/service"
+	"github.com/user/go-ecommerce/pkg/utils"
 
 	fiberSwagger "github.com/swaggo/fiber-swagger" // fiber-swagger middleware
 	_ "github.com/user/go-ecommerce/docs"          // docs is generated by Swag CLI
@@ -40,10 +44,26 @@ func main() {
 	}
 
 	cfg := config.LoadConfig()
-	
+
 	// Database Connection
 	infrastructure.ConnectDB(cfg)
 
+	// JWT signing keys, HS256 with JWT_SECRET unless a key ring is configured
+	if cfg.JWT.KeysDir != "" {
+		keyRing, err := utils.LoadKeyRing(cfg.JWT.KeysDir)
+		if err != nil {
+			log.Fatalf("Failed to load JWT keys: %v", err)
+		}
+		keyRing.StartAutoReload(utils.ParseDurationOrDefault(cfg.JWT.KeysReloadInterval, time.Minute))
+		utils.UseKeyRing(keyRing)
+	} else if cfg.JWT.Secret == "secret" {
+		log.Println("WARNING: JWT_SECRET is the default value, set it or configure JWT_KEYS_DIR")
+	}
+
+	if err := utils.ValidatePasswordConfig(cfg); err != nil {
+		log.Fatalf("Invalid password hashing settings: %v", err)
+	}
+
 	// Repositories
 	userRepo := repository.NewUserRepository(infrastructure.DB)
 	categoryRepo := repository.NewCategoryRepository(infrastructure.DB)
@@ -52,38 +72,109 @@ func main() {
 	orderRepo := repository.NewOrderRepository(infrastructure.DB)
 	addressRepo := repository.NewAddressRepository(infrastructure.DB)
 	wishlistRepo := repository.NewWishlistRepository(infrastructure.DB)
+	roleRepo := repository.NewRoleRepository(infrastructure.DB)
+	permissionRepo := repository.NewPermissionRepository(infrastructure.DB)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(infrastructure.DB)
+	sessionRepo := repository.NewSessionRepository(infrastructure.DB)
+	userTokenRepo := repository.NewUserTokenRepository(infrastructure.DB)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(infrastructure.DB)
+	identityRepo := repository.NewUserIdentityRepository(infrastructure.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(infrastructure.DB)
+	accountErasureRepo := repository.NewAccountErasureRepository(infrastructure.DB)
+	auditEventRepo := repository.NewAuditEventRepository(infrastructure.DB)
+	shopRepo := repository.NewShopRepository(infrastructure.DB)
+	customerGroupRepo := repository.NewCustomerGroupRepository(infrastructure.DB)
+	variantRepo := repository.NewVariantRepository(infrastructure.DB)
+	productImageRepo := repository.NewProductImageRepository(infrastructure.DB)
+	loginAttemptStore := repository.NewMemoryLoginAttemptStore()
+
+	// The permission catalog lives in code, the database follows it
+	if err := permissionRepo.SyncCatalog(context.Background(), domain.PermissionCatalog); err != nil {
+		log.Fatalf("Failed to sync permission catalog: %v", err)
+	}
+
+	// External integrations
+	mailer := infrastructure.NewMailer(cfg)
+	oidcProviders := infrastructure.NewOIDCProviders(cfg)
+	storage, err := infrastructure.NewStorage(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up file storage: %v", err)
+	}
 
 	// Services
-	userService := service.NewUserService(userRepo, cfg)
+	auditLogger := service.NewAuditLogger(auditEventRepo)
+	revocationStore := service.NewRevocationStore(sessionRepo, userRepo)
+	sessionService := service.NewSessionService(sessionRepo, refreshTokenRepo, revocationStore)
+	loginThrottler := service.NewLoginThrottler(loginAttemptStore, cfg)
+	passwordPolicy, err := service.NewPasswordPolicy(cfg)
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+	rbacService := service.NewRBACService(roleRepo)
+	twoFactorService := service.NewTwoFactorService(userRepo, recoveryCodeRepo, sessionService, cfg)
+	userService := service.NewUserService(userRepo, refreshTokenRepo, userTokenRepo, identityRepo, roleRepo, sessionService, loginThrottler, passwordPolicy, twoFactorService, rbacService, mailer, cfg)
+	oidcService := service.NewOIDCService(oidcProviders, userService)
 	categoryService := service.NewCategoryService(categoryRepo)
-	productService := service.NewProductService(productRepo, categoryRepo)
-	cartService := service.NewCartService(cartRepo, productRepo)
-	orderService := service.NewOrderService(orderRepo, cartRepo, productRepo, infrastructure.DB)
+	productService := service.NewProductService(productRepo, categoryRepo, shopRepo, storage)
+	variantService := service.NewVariantService(variantRepo, productRepo)
+	productImageService := service.NewProductImageService(productImageRepo, productRepo, storage, cfg)
+	pricingService := service.NewPricingService(userRepo, customerGroupRepo)
+	cartService := service.NewCartService(cartRepo, productRepo, pricingService, cfg)
+	cartService.StartGuestCartCleanup(time.Hour)
+	orderService := service.NewOrderService(orderRepo, cartRepo, productRepo, userRepo, pricingService, infrastructure.DB, cfg)
 	addressService := service.NewAddressService(addressRepo)
 	wishlistService := service.NewWishlistService(wishlistRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, rbacService)
+	adminUserService := service.NewAdminUserService(userRepo, roleRepo, sessionService, rbacService, revocationStore, cfg)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, userRepo, rbacService, cfg)
+	shopService := service.NewShopService(shopRepo)
+	customerGroupService := service.NewCustomerGroupService(customerGroupRepo, userRepo, productRepo, categoryRepo)
+	privacyService := service.NewPrivacyService(userRepo, addressRepo, wishlistRepo, cartRepo, orderRepo, accountErasureRepo, sessionService, twoFactorService)
 
 	// Handlers
-	authHandler := handler.NewAuthHandler(userService, cfg)
+	authHandler := handler.NewAuthHandler(userService, sessionService, twoFactorService, oidcService, privacyService, cartService, auditLogger, cfg)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	productHandler := handler.NewProductHandler(productService)
-	cartHandler := handler.NewCartHandler(cartService)
+	productHandler := handler.NewProductHandler(productService, pricingService, auditLogger)
+	variantHandler := handler.NewVariantHandler(variantService, auditLogger)
+	productImageHandler := handler.NewProductImageHandler(productImageService, auditLogger)
+	cartHandler := handler.NewCartHandler(cartService, cfg)
 	orderHandler := handler.NewOrderHandler(orderService)
 	addressHandler := handler.NewAddressHandler(addressService)
 	wishlistHandler := handler.NewWishlistHandler(wishlistService)
+	adminUserHandler := handler.NewAdminUserHandler(userService, adminUserService, orderService, addressService, customerGroupService, auditLogger)
+	jwksHandler := handler.NewJWKSHandler()
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, auditLogger)
+	roleHandler := handler.NewRoleHandler(roleService, auditLogger)
+	auditHandler := handler.NewAuditHandler(auditLogger)
+	shopHandler := handler.NewShopHandler(shopService, productService, pricingService, auditLogger)
+	sellerHandler := handler.NewSellerHandler(shopService, productService, auditLogger)
+	customerGroupHandler := handler.NewCustomerGroupHandler(customerGroupService, auditLogger)
 
 	// Initialize Fiber
 	app := fiber.New(fiber.Config{
 		AppName: cfg.Server.AppName,
+		// Room for an image upload plus the rest of the multipart form
+		BodyLimit: cfg.Storage.MaxImageSize + 1024*1024,
 	})
 
 	// Middleware
+	authMiddleware := middleware.AuthMiddleware(cfg, revocationStore, nil)
+	optionalAuthMiddleware := middleware.OptionalAuthMiddleware(cfg, revocationStore)
+	// Also accepts X-API-Key, only used on routes guarded by RequirePermission
+	apiKeyAuthMiddleware := middleware.AuthMiddleware(cfg, revocationStore, apiKeyService)
+	// Support staff acting as a customer can look around, but not buy or change the account
+	rejectImpersonation := middleware.RejectImpersonation()
+
 	app.Use(logger.New())
+	app.Use(middleware.AuditImpersonation(auditLogger))
 	app.Use(recover.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3000,http://localhost:3001,http://127.0.0.1:3000",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, " + middleware.CSRFHeader,
 		AllowCredentials: true,
 	}))
+	// Cookie-authenticated writes must repeat the csrf_token cookie in X-CSRF-Token
+	app.Use(middleware.CSRFProtection(cfg))
 
 	// Routes
 	app.Get("/health", func(c *fiber.Ctx) error {
@@ -94,58 +185,166 @@ func main() {
 		})
 	})
 
+	app.Get("/.well-known/jwks.json", jwksHandler.Get)
+
+	// Uploaded files of the local storage driver, S3 serves its own
+	if cfg.Storage.Driver != "s3" {
+		app.Static(infrastructure.LocalStorageRoute, cfg.Storage.LocalDir, fiber.Static{MaxAge: 86400})
+	}
+
 	api := app.Group("/api")
 	auth := api.Group("/auth")
 	auth.Post("/register", authHandler.Register)
 	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.Refresh)
 	auth.Post("/logout", authHandler.Logout)
+	auth.Get("/csrf", authHandler.CSRFToken)
+	auth.Post("/password/forgot", authHandler.ForgotPassword)
+	auth.Post("/password/reset", authHandler.ResetPassword)
+	auth.Post("/email/verify", authHandler.VerifyEmail)
+	auth.Post("/email/resend", authMiddleware, rejectImpersonation, authHandler.ResendVerification)
+	auth.Get("/me", authMiddleware, authHandler.Me)
+	auth.Patch("/me", authMiddleware, rejectImpersonation, authHandler.UpdateMe)
+	auth.Delete("/me", authMiddleware, rejectImpersonation, authHandler.DeleteAccount)
+	auth.Get("/me/export", authMiddleware, rejectImpersonation, authHandler.ExportData)
+	auth.Post("/me/password", authMiddleware, rejectImpersonation, authHandler.ChangePassword)
+	auth.Get("/sessions", authMiddleware, authHandler.ListSessions)
+	auth.Delete("/sessions", authMiddleware, rejectImpersonation, authHandler.RevokeAllSessions)
+	auth.Delete("/sessions/:id", authMiddleware, rejectImpersonation, authHandler.RevokeSession)
+	auth.Get("/oidc/:provider/login", authHandler.OIDCLogin)
+	auth.Get("/oidc/:provider/callback", authHandler.OIDCCallback)
+	auth.Post("/2fa/verify", authHandler.VerifyTwoFactor)
+	auth.Post("/2fa/setup", authMiddleware, rejectImpersonation, authHandler.SetupTwoFactor)
+	auth.Post("/2fa/enable", authMiddleware, rejectImpersonation, authHandler.EnableTwoFactor)
+	auth.Post("/2fa/disable", authMiddleware, rejectImpersonation, authHandler.DisableTwoFactor)
+	auth.Post("/2fa/recovery-codes", authMiddleware, rejectImpersonation, authHandler.RegenerateRecoveryCodes)
 
 	// Swagger Route
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
 	// Protected Routes (Require Auth)
-	admin := api.Group("/admin", middleware.AuthMiddleware(cfg)) 
-	admin.Get("/orders", orderHandler.GetAllOrders)
+	admin := api.Group("/admin")
+	admin.Get("/orders", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionOrderReadAll), orderHandler.GetAllOrders)
+	admin.Get("/users", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserRead), adminUserHandler.FindAll)
+	admin.Get("/users/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserRead), adminUserHandler.FindByID)
+	admin.Get("/users/:id/orders", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserRead), adminUserHandler.GetOrders)
+	admin.Get("/users/:id/addresses", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserRead), adminUserHandler.GetAddresses)
+	admin.Put("/users/:id/role", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserManage), adminUserHandler.ChangeRole)
+	admin.Post("/users/:id/disable", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserManage), adminUserHandler.Disable)
+	admin.Post("/users/:id/enable", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserManage), adminUserHandler.Enable)
+	admin.Put("/users/:id/customer-group", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), adminUserHandler.ChangeCustomerGroup)
+	admin.Post("/users/:id/unlock", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionUserUnlock), adminUserHandler.Unlock)
+	// Interactive only, and an impersonation can't start another one
+	admin.Post("/users/:id/impersonate", authMiddleware, rejectImpersonation, middleware.RequirePermission(rbacService, domain.PermissionUserImpersonate), adminUserHandler.Impersonate)
+	// Keys can't manage keys, only a signed-in admin can
+	admin.Get("/api-keys", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionAPIKeyManage), apiKeyHandler.List)
+	admin.Post("/api-keys", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionAPIKeyManage), apiKeyHandler.Create)
+	admin.Delete("/api-keys/:id", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionAPIKeyManage), apiKeyHandler.Revoke)
+	// Same for roles, they decide what keys and users can do
+	admin.Get("/roles", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.FindAll)
+	admin.Get("/roles/:id", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.FindByID)
+	admin.Post("/roles", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.Create)
+	admin.Put("/roles/:id", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.Update)
+	admin.Delete("/roles/:id", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.Delete)
+	admin.Post("/roles/:id/permissions", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.AttachPermissions)
+	admin.Delete("/roles/:id/permissions/:name", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.DetachPermission)
+	admin.Get("/permissions", authMiddleware, middleware.RequirePermission(rbacService, domain.PermissionRoleManage), roleHandler.ListPermissions)
+
+	admin.Get("/audit-events", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionAuditRead), auditHandler.FindAll)
+
+	admin.Get("/shops", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionShopReview), shopHandler.FindAll)
+	admin.Post("/shops/:id/approve", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionShopReview), shopHandler.Approve)
+	admin.Post("/shops/:id/reject", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionShopReview), shopHandler.Reject)
+	admin.Post("/shops/:id/suspend", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionShopReview), shopHandler.Suspend)
+
+	admin.Get("/customer-groups", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.FindAll)
+	admin.Get("/customer-groups/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.FindByID)
+	admin.Post("/customer-groups", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.Create)
+	admin.Put("/customer-groups/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.Update)
+	admin.Delete("/customer-groups/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.Delete)
+	admin.Post("/customer-groups/:id/prices", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.SetPrice)
+	admin.Delete("/customer-groups/:id/prices/:ruleId", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCustomerGroupManage), customerGroupHandler.DeletePrice)
 
 	// Category Routes
 	categories := api.Group("/categories")
 	categories.Get("/", categoryHandler.FindAll)
 	categories.Get("/:id", categoryHandler.FindByID)
-	categories.Post("/", middleware.AuthMiddleware(cfg), categoryHandler.Create)
-	categories.Put("/:id", middleware.AuthMiddleware(cfg), categoryHandler.Update)
-	categories.Delete("/:id", middleware.AuthMiddleware(cfg), categoryHandler.Delete)
+	categories.Post("/", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCategoryCreate), categoryHandler.Create)
+	categories.Put("/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCategoryUpdate), categoryHandler.Update)
+	categories.Delete("/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionCategoryDelete), categoryHandler.Delete)
 
 	// Product Routes
 	products := api.Group("/products")
-	products.Get("/", productHandler.FindAll)
-	products.Get("/:id", productHandler.FindByID)
-	products.Get("/slug/:slug", productHandler.FindBySlug)
-	products.Post("/", middleware.AuthMiddleware(cfg), productHandler.Create)
-	products.Put("/:id", middleware.AuthMiddleware(cfg), productHandler.Update)
-	products.Delete("/:id", middleware.AuthMiddleware(cfg), productHandler.Delete)
+	// Signed-in customers see their customer group's prices as effective_price
+	products.Get("/", optionalAuthMiddleware, productHandler.FindAll)
+	products.Get("/:id", optionalAuthMiddleware, productHandler.FindByID)
+	products.Get("/slug/:slug", optionalAuthMiddleware, productHandler.FindBySlug)
+	products.Post("/", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductCreate), productHandler.Create)
+	products.Put("/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), productHandler.Update)
+	products.Delete("/:id", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductDelete), productHandler.Delete)
+	// Options and variants are part of the product, changing them needs product:update
+	products.Get("/:id/variants", variantHandler.FindAll)
+	products.Post("/:id/options", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), variantHandler.AddOption)
+	products.Delete("/:id/options/:optionId", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), variantHandler.DeleteOption)
+	products.Post("/:id/variants", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), variantHandler.CreateVariant)
+	products.Put("/:id/variants/:variantId", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), variantHandler.UpdateVariant)
+	products.Delete("/:id/variants/:variantId", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), variantHandler.DeleteVariant)
+	// So is the image gallery
+	products.Get("/:id/images", productImageHandler.FindAll)
+	products.Post("/:id/images", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), productImageHandler.Upload)
+	products.Put("/:id/images/order", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), productImageHandler.Reorder)
+	products.Put("/:id/images/:imageId", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), productImageHandler.Update)
+	products.Delete("/:id/images/:imageId", apiKeyAuthMiddleware, middleware.RequirePermission(rbacService, domain.PermissionProductUpdate), productImageHandler.Delete)
+
+	// Shop Routes (public pages of approved sellers)
+	shops := api.Group("/shops")
+	shops.Get("/:slug", shopHandler.FindBySlug)
+	shops.Get("/:slug/products", optionalAuthMiddleware, shopHandler.GetProducts)
+
+	// Seller Routes
+	// Any user can apply for a shop, the catalog routes only work once it's approved
+	// and only ever touch that shop's products
+	seller := api.Group("/seller", authMiddleware)
+	seller.Post("/shop", rejectImpersonation, sellerHandler.ApplyShop)
+	seller.Get("/shop", sellerHandler.GetShop)
+	seller.Put("/shop", rejectImpersonation, sellerHandler.UpdateShop)
+	seller.Get("/products", sellerHandler.FindProducts)
+	seller.Post("/products", rejectImpersonation, sellerHandler.CreateProduct)
+	seller.Put("/products/:id", rejectImpersonation, sellerHandler.UpdateProduct)
+	seller.Delete("/products/:id", rejectImpersonation, sellerHandler.DeleteProduct)
+	seller.Post("/products/:id/options", rejectImpersonation, sellerHandler.RequireOwnProduct, variantHandler.AddOption)
+	seller.Delete("/products/:id/options/:optionId", rejectImpersonation, sellerHandler.RequireOwnProduct, variantHandler.DeleteOption)
+	seller.Post("/products/:id/variants", rejectImpersonation, sellerHandler.RequireOwnProduct, variantHandler.CreateVariant)
+	seller.Put("/products/:id/variants/:variantId", rejectImpersonation, sellerHandler.RequireOwnProduct, variantHandler.UpdateVariant)
+	seller.Delete("/products/:id/variants/:variantId", rejectImpersonation, sellerHandler.RequireOwnProduct, variantHandler.DeleteVariant)
+	seller.Post("/products/:id/images", rejectImpersonation, sellerHandler.RequireOwnProduct, productImageHandler.Upload)
+	seller.Put("/products/:id/images/order", rejectImpersonation, sellerHandler.RequireOwnProduct, productImageHandler.Reorder)
+	seller.Put("/products/:id/images/:imageId", rejectImpersonation, sellerHandler.RequireOwnProduct, productImageHandler.Update)
+	seller.Delete("/products/:id/images/:imageId", rejectImpersonation, sellerHandler.RequireOwnProduct, productImageHandler.Delete)
 
 	// Cart Routes
-	cart := api.Group("/cart", middleware.AuthMiddleware(cfg))
+	// Guests get a cart too, merged into their own when they log in or register
+	cart := api.Group("/cart", optionalAuthMiddleware)
 	cart.Get("/", cartHandler.GetCart)
-	cart.Post("/", cartHandler.AddToCart)
-	cart.Put("/items/:id", cartHandler.UpdateItem)
-	cart.Delete("/items/:id", cartHandler.RemoveItem)
+	cart.Post("/", rejectImpersonation, cartHandler.AddToCart)
+	cart.Put("/items/:id", rejectImpersonation, cartHandler.UpdateItem)
+	cart.Delete("/items/:id", rejectImpersonation, cartHandler.RemoveItem)
 
 	// Order Routes
-	orders := api.Group("/orders", middleware.AuthMiddleware(cfg))
-	orders.Post("/checkout", orderHandler.Checkout)
+	orders := api.Group("/orders", authMiddleware)
+	orders.Post("/checkout", rejectImpersonation, orderHandler.Checkout)
 	orders.Get("/", orderHandler.GetMyOrders)
 
 	// Address Routes
-	addresses := api.Group("/addresses", middleware.AuthMiddleware(cfg))
-	addresses.Post("/", addressHandler.Create)
+	addresses := api.Group("/addresses", authMiddleware)
+	addresses.Post("/", rejectImpersonation, addressHandler.Create)
 	addresses.Get("/", addressHandler.GetMyAddresses)
-	addresses.Put("/:id", addressHandler.Update)
-	addresses.Delete("/:id", addressHandler.Delete)
+	addresses.Put("/:id", rejectImpersonation, addressHandler.Update)
+	addresses.Delete("/:id", rejectImpersonation, addressHandler.Delete)
 
 	// Wishlist Routes
-	wishlist := api.Group("/wishlist", middleware.AuthMiddleware(cfg))
-	wishlist.Post("/toggle", wishlistHandler.Toggle)
+	wishlist := api.Group("/wishlist", authMiddleware)
+	wishlist.Post("/toggle", rejectImpersonation, wishlistHandler.Toggle)
 	wishlist.Get("/", wishlistHandler.GetMyWishlist)
 
 	// Start Server
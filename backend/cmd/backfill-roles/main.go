@@ -0,0 +1,40 @@
+// Command backfill-roles assigns the default role (DEFAULT_ROLE) to every user without one.
+// Run it before enabling RBAC_ENFORCED on a database with accounts created before
+// registration assigned roles.
+//
+//	go run cmd/backfill-roles/main.go
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/infrastructure"
+	"github.com/user/go-ecommerce/internal/repository"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found or error loading it")
+	}
+
+	cfg := config.LoadConfig()
+	infrastructure.ConnectDB(cfg)
+
+	ctx := context.Background()
+	roleRepo := repository.NewRoleRepository(infrastructure.DB)
+	userRepo := repository.NewUserRepository(infrastructure.DB)
+
+	role, err := roleRepo.FindByName(ctx, cfg.Auth.DefaultRole)
+	if err != nil {
+		log.Fatalf("Failed to find default role %q: %v", cfg.Auth.DefaultRole, err)
+	}
+
+	count, err := userRepo.AssignRoleWhereMissing(ctx, role.ID)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+	log.Printf("Assigned role %q to %d users", role.Name, count)
+}
@@ -19,9 +19,27 @@ func main() {
 	infrastructure.ConnectDB(cfg)
 
 	fmt.Println("Running Migrations...")
-	err := infrastructure.DB.AutoMigrate(&domain.User{}, &domain.Role{}, &domain.Permission{}, &domain.Category{}, &domain.Product{}, &domain.Cart{}, &domain.CartItem{}, &domain.Order{}, &domain.OrderItem{}, &domain.Address{}, &domain.Wishlist{})
+	err := infrastructure.DB.AutoMigrate(&domain.User{}, &domain.Role{}, &domain.Permission{}, &domain.Category{}, &domain.Shop{}, &domain.Product{}, &domain.ProductOption{}, &domain.ProductVariant{}, &domain.VariantOptionValue{}, &domain.ProductImage{}, &domain.CustomerGroup{}, &domain.GroupPriceRule{}, &domain.Cart{}, &domain.CartItem{}, &domain.Order{}, &domain.OrderItem{}, &domain.Address{}, &domain.Wishlist{}, &domain.RefreshToken{}, &domain.Session{}, &domain.UserToken{}, &domain.RecoveryCode{}, &domain.UserIdentity{}, &domain.APIKey{}, &domain.AuditEvent{})
 	if err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
+
+	// The audit log is append-only, even for code that holds the database credentials
+	if err := infrastructure.DB.Exec(auditEventsAppendOnly).Error; err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
 	fmt.Println("Migrations executed successfully")
 }
+
+const auditEventsAppendOnly = `
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+	BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+`
@@ -12,8 +12,8 @@ import (
 	"github.com/user/go-ecommerce/internal/config"
 	"github.com/user/go-ecommerce/internal/domain"
 	"github.com/user/go-ecommerce/internal/infrastructure"
+	"github.com/user/go-ecommerce/internal/repository"
 	"github.com/user/go-ecommerce/pkg/utils"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -30,18 +30,21 @@ func main() {
 
 	log.Println("Seeding Database...")
 
-	// 1. Seed Users
-	seedUsers(db, ctx)
+	// 1. Seed Permissions
+	seedPermissions(db, ctx)
 
-	// 2. Seed Categories & Products
+	// 2. Seed Users
+	seedUsers(db, ctx, cfg)
+
+	// 3. Seed Categories & Products
 	seedCategoriesAndProducts(db, ctx)
 
 	log.Println("Seeding Completed Successfully!")
 }
 
-func seedUsers(db *gorm.DB, ctx context.Context) {
+func seedUsers(db *gorm.DB, ctx context.Context, cfg *config.Config) {
 	// 1. Ensure Roles Exist
-	roles := []string{"admin", "user"}
+	roles := []string{domain.RoleAdmin, domain.RoleUser}
 	roleMap := make(map[string]uuid.UUID)
 
 	for _, rName := range roles {
@@ -52,6 +55,8 @@ func seedUsers(db *gorm.DB, ctx context.Context) {
 				role = domain.Role{
 					ID:   uuid.New(),
 					Name: rName,
+					// Admins can change the whole catalog, so they must sign in with 2FA
+					RequireTwoFactor: rName == domain.RoleAdmin,
 				}
 				if err := db.WithContext(ctx).Create(&role).Error; err != nil {
 					log.Printf("Failed to create role %s: %v", rName, err)
@@ -63,13 +68,19 @@ func seedUsers(db *gorm.DB, ctx context.Context) {
 			}
 		}
 		roleMap[rName] = role.ID
+
+		assignRolePermissions(db, ctx, &role, rolePermissions(rName))
 	}
 
 	// 2. Seed Users
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	// Seeded directly, the password policy only applies to passwords chosen through the API
+	hashedPassword, err := utils.HashPassword("password123", cfg)
+	if err != nil {
+		log.Fatalf("Failed to hash seed password: %v", err)
+	}
 
-	adminRoleID := roleMap["admin"]
-	userRoleID := roleMap["user"]
+	adminRoleID := roleMap[domain.RoleAdmin]
+	userRoleID := roleMap[domain.RoleUser]
 
 	users := []struct {
 		Name   string
@@ -88,6 +99,8 @@ func seedUsers(db *gorm.DB, ctx context.Context) {
 		},
 	}
 
+	verifiedAt := time.Now() // Seeded accounts don't go through email verification
+
 	for _, u := range users {
 		var existing domain.User
 		if err := db.WithContext(ctx).Where("email = ?", u.Email).First(&existing).Error; err == nil {
@@ -96,11 +109,12 @@ func seedUsers(db *gorm.DB, ctx context.Context) {
 		}
 
 		user := domain.User{
-			ID:       uuid.New(),
-			Name:     u.Name,
-			Email:    u.Email,
-			Password: string(hashedPassword),
-			RoleID:   &u.RoleID,
+			ID:         uuid.New(),
+			Name:       u.Name,
+			Email:      u.Email,
+			Password:   hashedPassword,
+			RoleID:     &u.RoleID,
+			VerifiedAt: &verifiedAt,
 		}
 
 		if err := db.WithContext(ctx).Create(&user).Error; err != nil {
@@ -111,13 +125,54 @@ func seedUsers(db *gorm.DB, ctx context.Context) {
 	}
 }
 
+func seedPermissions(db *gorm.DB, ctx context.Context) {
+	// Same sync the API runs at startup
+	if err := repository.NewPermissionRepository(db).SyncCatalog(ctx, domain.PermissionCatalog); err != nil {
+		log.Printf("Failed to sync permissions: %v", err)
+		return
+	}
+	log.Printf("Synced %d permissions", len(domain.PermissionCatalog))
+}
+
+// rolePermissions returns the permission names granted to a seeded role.
+// Admin gets the whole catalog; user gets none, the cart, checkout and own orders
+// only require a signed-in user (or none at all for the cart).
+func rolePermissions(roleName string) []string {
+	if roleName == domain.RoleAdmin {
+		names := make([]string, 0, len(domain.PermissionCatalog))
+		for _, p := range domain.PermissionCatalog {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+	return nil
+}
+
+func assignRolePermissions(db *gorm.DB, ctx context.Context, role *domain.Role, names []string) {
+	if role.ID == uuid.Nil {
+		return
+	}
+
+	var permissions []domain.Permission
+	if err := db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		log.Printf("Failed to load permissions for role %s: %v", role.Name, err)
+		return
+	}
+
+	if err := db.WithContext(ctx).Model(role).Association("Permissions").Replace(permissions); err != nil {
+		log.Printf("Failed to assign permissions to role %s: %v", role.Name, err)
+		return
+	}
+	log.Printf("Assigned %d permissions to role: %s", len(permissions), role.Name)
+}
+
 func seedCategoriesAndProducts(db *gorm.DB, ctx context.Context) {
 	categories := []string{"Elektronik", "Fashion Pria", "Fashion Wanita", "Rumah Tangga", "Kesehatan", "Hobi & Mainan"}
-	
+
 	for _, catName := range categories {
 		catSlug := utils.MakeSlug(catName)
 		var category domain.Category
-		
+
 		// Find or Create Category
 		err := db.WithContext(ctx).Where("slug = ?", catSlug).First(&category).Error
 		if err != nil {
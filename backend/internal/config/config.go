@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -9,11 +11,19 @@ type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	Cookie   CookieConfig
+	CSRF     CSRFConfig
+	Auth     AuthConfig
+	Password PasswordConfig
+	Cart     CartConfig
+	Mail     MailConfig
+	Storage  StorageConfig
+	OIDC     OIDCConfig
 }
 
 type ServerConfig struct {
-	Port    string
-	AppName string
+	Port        string
+	AppName     string
+	FrontendURL string // Used to build links in emails
 }
 
 type DatabaseConfig struct {
@@ -25,42 +35,214 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string
-	Expiry string
-}
-	
-	type CookieConfig struct {
-		Domain   string
-		Secure   bool
-		HTTPOnly bool
-		SameSite string
+	Secret        string // HS256 secret, only used when KeysDir is empty
+	Expiry        string // Access token lifetime
+	RefreshExpiry string
+	// Directory of RS256/EdDSA private keys, enables asymmetric signing and /.well-known/jwks.json
+	KeysDir            string
+	KeysReloadInterval string
+}
+
+type CookieConfig struct {
+	Domain   string
+	Secure   bool
+	HTTPOnly bool
+	SameSite string
+}
+
+type CSRFConfig struct {
+	Enabled bool
+	// Path prefixes that skip the check, for callers that can't send the header (e.g. payment webhooks)
+	ExemptPaths []string
+}
+
+type AuthConfig struct {
+	PasswordResetExpiry             string
+	EmailVerificationExpiry         string
+	VerificationResendInterval      string // Minimum time between verification emails
+	RequireVerifiedEmailForCheckout bool
+
+	// Brute-force protection for /auth/login
+	LoginMaxAccountFailures int
+	LoginMaxIPFailures      int
+	LoginFailureWindow      string
+	LoginLockoutBase        string // First lockout duration, doubled on every further failure
+	LoginLockoutMax         string
+
+	// Role given to self-registered and OIDC users, looked up by name
+	DefaultRole string
+	// RBACEnforced rejects access tokens without a role claim. Only turn it off
+	// until cmd/backfill-roles has assigned a role to every existing user.
+	RBACEnforced bool
+
+	// Lifetime of the tokens support staff get from /admin/users/:id/impersonate
+	ImpersonationExpiry string
+}
+
+type PasswordConfig struct {
+	// New hashes use Algorithm ("argon2id" or "bcrypt"), hashes with another
+	// algorithm or other cost parameters are replaced on the next login
+	Algorithm         string
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+
+	MinLength int
+	// Extra common/breached passwords, one per line, rejected on top of the built-in list
+	BlocklistFile string
+}
+
+type CartConfig struct {
+	// Lifetime of the signed cookie identifying an anonymous visitor's cart
+	GuestExpiry string
+}
+
+type MailConfig struct {
+	Driver       string // "smtp" or "log"
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutputDir    string // Used by the log driver, empty means stdout only
+}
+
+type StorageConfig struct {
+	Driver       string // "local" or "s3"
+	MaxImageSize int    // Largest accepted product image, in bytes
+	// Files are served from PublicURL/{key}. For the local driver this is the /uploads
+	// route of this API, for S3 it defaults to the bucket's URL on the endpoint.
+	PublicURL string
+	LocalDir  string // Used by the local driver
+	// S3-compatible object storage (AWS S3, MinIO, ...)
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool // {endpoint}/{bucket}/{key} instead of {bucket}.{endpoint host}/{key}, MinIO needs it
+}
+
+type OIDCConfig struct {
+	// Public URL of this API, callbacks go to {CallbackBaseURL}/api/auth/oidc/{provider}/callback
+	CallbackBaseURL string
+	Providers       []OIDCProviderConfig
+}
+
+type OIDCProviderConfig struct {
+	Name         string // Used in URLs and stored on linked identities, e.g. "google"
+	IssuerURL    string // Discovery is loaded from {IssuerURL}/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+func LoadConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:        getEnv("PORT", "8080"),
+			AppName:     getEnv("APP_NAME", "Go Fiber App"),
+			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:3000"),
+		},
+		Database: DatabaseConfig{
+			Host:     getEnv("DB_HOST", "localhost"),
+			Port:     getEnv("DB_PORT", "5432"),
+			User:     getEnv("DB_USER", "postgres"),
+			Password: getEnv("DB_PASSWORD", "postgres"),
+			Name:     getEnv("DB_NAME", "go_ecommerce"),
+		},
+		JWT: JWTConfig{
+			Secret:             getEnv("JWT_SECRET", "secret"),
+			Expiry:             getEnv("JWT_EXPIRY", "15m"),
+			RefreshExpiry:      getEnv("JWT_REFRESH_EXPIRY", "720h"),
+			KeysDir:            getEnv("JWT_KEYS_DIR", ""),
+			KeysReloadInterval: getEnv("JWT_KEYS_RELOAD_INTERVAL", "1m"),
+		},
+		Cookie: CookieConfig{
+			Domain:   getEnv("COOKIE_DOMAIN", ""),
+			Secure:   getEnv("COOKIE_SECURE", "false") == "true",
+			HTTPOnly: getEnv("COOKIE_HTTP_ONLY", "true") == "true",
+			SameSite: getEnv("COOKIE_SAME_SITE", "Lax"),
+		},
+		CSRF: CSRFConfig{
+			Enabled:     getEnv("CSRF_ENABLED", "true") == "true",
+			ExemptPaths: getEnvList("CSRF_EXEMPT_PATHS"),
+		},
+		Auth: AuthConfig{
+			PasswordResetExpiry:             getEnv("PASSWORD_RESET_EXPIRY", "1h"),
+			EmailVerificationExpiry:         getEnv("EMAIL_VERIFICATION_EXPIRY", "24h"),
+			VerificationResendInterval:      getEnv("VERIFICATION_RESEND_INTERVAL", "1m"),
+			RequireVerifiedEmailForCheckout: getEnv("REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT", "false") == "true",
+			LoginMaxAccountFailures:         getEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			LoginMaxIPFailures:              getEnvInt("LOGIN_MAX_IP_FAILURES", 20),
+			LoginFailureWindow:              getEnv("LOGIN_FAILURE_WINDOW", "15m"),
+			LoginLockoutBase:                getEnv("LOGIN_LOCKOUT_BASE", "1m"),
+			LoginLockoutMax:                 getEnv("LOGIN_LOCKOUT_MAX", "1h"),
+			DefaultRole:                     getEnv("DEFAULT_ROLE", "user"),
+			RBACEnforced:                    getEnv("RBAC_ENFORCED", "true") == "true",
+			ImpersonationExpiry:             getEnv("IMPERSONATION_EXPIRY", "15m"),
+		},
+		Password: PasswordConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			Argon2Memory:      getEnvInt("PASSWORD_ARGON2_MEMORY", 64*1024),
+			Argon2Iterations:  getEnvInt("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvInt("PASSWORD_ARGON2_PARALLELISM", 2),
+			BcryptCost:        getEnvInt("PASSWORD_BCRYPT_COST", 12),
+			MinLength:         getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			BlocklistFile:     getEnv("PASSWORD_BLOCKLIST_FILE", ""),
+		},
+		Cart: CartConfig{
+			GuestExpiry: getEnv("GUEST_CART_EXPIRY", "720h"),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@localhost"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			OutputDir:    getEnv("MAIL_OUTPUT_DIR", ""),
+		},
+		Storage: StorageConfig{
+			Driver:       getEnv("STORAGE_DRIVER", "local"),
+			MaxImageSize: getEnvInt("STORAGE_MAX_IMAGE_SIZE", 5*1024*1024),
+			PublicURL:    getEnv("STORAGE_PUBLIC_URL", ""),
+			LocalDir:     getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			S3Endpoint:   getEnv("S3_ENDPOINT", "http://localhost:9000"),
+			S3Region:     getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:     getEnv("S3_BUCKET", ""),
+			S3AccessKey:  getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:  getEnv("S3_SECRET_KEY", ""),
+			S3PathStyle:  getEnv("S3_PATH_STYLE", "true") == "true",
+		},
+		OIDC: OIDCConfig{
+			CallbackBaseURL: getEnv("OIDC_CALLBACK_BASE_URL", "http://localhost:8080"),
+			Providers:       loadOIDCProviders(),
+		},
 	}
+}
 
-	func LoadConfig() *Config {
-		return &Config{
-			Server: ServerConfig{
-				Port:    getEnv("PORT", "8080"),
-				AppName: getEnv("APP_NAME", "Go Fiber App"),
-			},
-			Database: DatabaseConfig{
-				Host:     getEnv("DB_HOST", "localhost"),
-				Port:     getEnv("DB_PORT", "5432"),
-				User:     getEnv("DB_USER", "postgres"),
-				Password: getEnv("DB_PASSWORD", "postgres"),
-				Name:     getEnv("DB_NAME", "go_ecommerce"),
-			},
-			JWT: JWTConfig{
-				Secret: getEnv("JWT_SECRET", "secret"),
-				Expiry: getEnv("JWT_EXPIRY", "24h"),
-			},
-			Cookie: CookieConfig{
-				Domain:   getEnv("COOKIE_DOMAIN", ""),
-				Secure:   getEnv("COOKIE_SECURE", "false") == "true",
-				HTTPOnly: getEnv("COOKIE_HTTP_ONLY", "true") == "true",
-				SameSite: getEnv("COOKIE_SAME_SITE", "Lax"),
-			},
+// loadOIDCProviders reads OIDC_PROVIDERS (e.g. "google,mock") and the
+// OIDC_<NAME>_ISSUER / _CLIENT_ID / _CLIENT_SECRET / _SCOPES variables of each provider.
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
 		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       strings.Fields(getEnv(prefix+"SCOPES", "openid email profile")),
+		})
 	}
+	return providers
+}
 
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
@@ -68,3 +250,23 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvList splits a comma-separated variable, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to spot (e.g. by secret scanners)
+const APIKeyPrefix = "gek_"
+
+// APIKey Entity
+// A credential for scripts and other services. The key acts on behalf of its creator,
+// limited to Scopes (permission names). Only a hash of the secret part is stored.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string     `json:"name" gorm:"not null"`
+	Prefix      string     `json:"prefix" gorm:"uniqueIndex;not null"` // Public part of the key, used for lookup
+	KeyHash     string     `json:"-" gorm:"not null"`
+	Scopes      []string   `json:"scopes" gorm:"serializer:json"`
+	CreatedByID uuid.UUID  `json:"created_by_id" gorm:"type:uuid;not null;index"`
+	CreatedBy   *User      `json:"-" gorm:"foreignKey:CreatedByID"`
+	ExpiresAt   *time.Time `json:"expires_at"` // Nil means the key doesn't expire
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	FindByPrefix(ctx context.Context, prefix string) (*APIKey, error) // Preloads CreatedBy
+	FindAll(ctx context.Context) ([]APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type APIKeyService interface {
+	// Create returns the stored key and the plain key, which is only available now
+	Create(ctx context.Context, creatorID uuid.UUID, req CreateAPIKeyRequest) (*APIKey, string, error)
+	List(ctx context.Context) ([]APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// Authenticate returns the key for a plain key from the X-API-Key header.
+	// Unknown, expired and revoked keys return ErrUnauthorized.
+	Authenticate(ctx context.Context, plainKey string) (*APIKey, error)
+}
+
+// DTOs
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions, "<target>.<what happened>"
+const (
+	AuditActionLogin       = "auth.login"
+	AuditActionLoginFailed = "auth.login_failed"
+	AuditActionLogout      = "auth.logout"
+
+	AuditActionUserRoleChanged  = "user.role_changed"
+	AuditActionUserDisabled     = "user.disabled"
+	AuditActionUserEnabled      = "user.enabled"
+	AuditActionUserUnlocked     = "user.unlocked"
+	AuditActionUserGroupChanged = "user.customer_group_changed"
+	AuditActionUserClaimed      = "user.account_claimed" // Unverified account taken over by the email's owner via OIDC
+	// Issuing the token, then one event per request made with it
+	AuditActionUserImpersonated    = "user.impersonated"
+	AuditActionImpersonatedRequest = "user.impersonated_request"
+
+	AuditActionRoleCreated            = "role.created"
+	AuditActionRoleUpdated            = "role.updated"
+	AuditActionRoleDeleted            = "role.deleted"
+	AuditActionRolePermissionsChanged = "role.permissions_changed"
+
+	AuditActionProductCreated = "product.created"
+	AuditActionProductUpdated = "product.updated"
+	AuditActionProductDeleted = "product.deleted"
+	// Target the product, before and after are the option or variant
+	AuditActionProductOptionCreated  = "product.option_created"
+	AuditActionProductOptionDeleted  = "product.option_deleted"
+	AuditActionProductVariantCreated = "product.variant_created"
+	AuditActionProductVariantUpdated = "product.variant_updated"
+	AuditActionProductVariantDeleted = "product.variant_deleted"
+	// Target the product, before and after are the image, or the whole gallery when reordering
+	AuditActionProductImageAdded      = "product.image_added"
+	AuditActionProductImageUpdated    = "product.image_updated"
+	AuditActionProductImageDeleted    = "product.image_deleted"
+	AuditActionProductImagesReordered = "product.images_reordered"
+
+	AuditActionShopApproved  = "shop.approved"
+	AuditActionShopRejected  = "shop.rejected"
+	AuditActionShopSuspended = "shop.suspended"
+
+	AuditActionCustomerGroupCreated       = "customer_group.created"
+	AuditActionCustomerGroupUpdated       = "customer_group.updated"
+	AuditActionCustomerGroupDeleted       = "customer_group.deleted"
+	AuditActionCustomerGroupPricesChanged = "customer_group.prices_changed"
+
+	AuditActionAPIKeyCreated = "api_key.created"
+	AuditActionAPIKeyRevoked = "api_key.revoked"
+)
+
+// Audit target types
+const (
+	AuditTargetUser          = "user"
+	AuditTargetRole          = "role"
+	AuditTargetProduct       = "product"
+	AuditTargetAPIKey        = "api_key"
+	AuditTargetShop          = "shop"
+	AuditTargetCustomerGroup = "customer_group"
+)
+
+// AuditEvent Entity. Rows are only ever inserted, the migration installs a trigger refusing updates and deletes.
+type AuditEvent struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ActorID    *uuid.UUID `json:"actor_id" gorm:"type:uuid;index"` // Nil when nobody is signed in, e.g. a failed login
+	APIKeyID   *uuid.UUID `json:"api_key_id" gorm:"type:uuid"`     // Set when the actor authenticated with an API key
+	Action     string     `json:"action" gorm:"not null;index"`
+	TargetType string     `json:"target_type" gorm:"index:idx_audit_events_target"`
+	TargetID   string     `json:"target_id" gorm:"index:idx_audit_events_target"` // A string, failed logins target the email that was tried
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	// Changed fields by JSON name, empty for events that don't change an entity
+	Changes   map[string]AuditChange `json:"changes,omitempty" gorm:"type:jsonb;serializer:json"`
+	Metadata  map[string]string      `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index"`
+}
+
+// AuditChange is the value of one field before and after the change, nil on the side where it didn't exist
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntry describes an event for AuditLogger.Record
+type AuditEntry struct {
+	ActorID    uuid.UUID
+	APIKeyID   uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	Client     ClientInfo
+	// Entity before and after the change, Before is nil for creations and After for deletions
+	Before   interface{}
+	After    interface{}
+	Metadata map[string]string
+}
+
+type AuditQueryParams struct {
+	Page       int
+	Limit      int
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditEventRepository interface {
+	Create(ctx context.Context, event *AuditEvent) error
+	FindAll(ctx context.Context, params AuditQueryParams) ([]AuditEvent, int64, error)
+}
+
+// AuditLogger writes the security audit log
+type AuditLogger interface {
+	// Record appends an event. A failed write is logged, it never fails the audited action.
+	Record(ctx context.Context, entry AuditEntry)
+	FindEvents(ctx context.Context, params AuditQueryParams) ([]AuditEvent, int64, error)
+}
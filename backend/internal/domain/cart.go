@@ -10,7 +10,7 @@ import (
 // Cart Entity
 type Cart struct {
 	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;unique"` // One cart per user
+	UserID    *uuid.UUID `json:"user_id" gorm:"type:uuid;unique"` // One cart per user, nil for guest carts
 	Items     []CartItem `json:"items" gorm:"foreignKey:CartID"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
@@ -25,21 +25,56 @@ type CartItem struct {
 	Quantity  int       `json:"quantity" gorm:"not null;check:quantity > 0"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Set for products with variants, the line goes away with the variant
+	VariantID *uuid.UUID      `json:"variant_id" gorm:"type:uuid;index"`
+	Variant   *ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+}
+
+// CartLine identifies a line of a cart, the same product in two variants is two lines
+type CartLine struct {
+	ProductID uuid.UUID
+	VariantID uuid.UUID // uuid.Nil for products without variants
+}
+
+func (i *CartItem) Line() CartLine {
+	line := CartLine{ProductID: i.ProductID}
+	if i.VariantID != nil {
+		line.VariantID = *i.VariantID
+	}
+	return line
+}
+
+// CartOwner is whose cart a request is for: a signed-in user, or a guest identified by the guest cart cookie
+type CartOwner struct {
+	UserID      uuid.UUID // uuid.Nil for guests
+	GuestCartID uuid.UUID // uuid.Nil until the guest adds something
+}
+
+func (o CartOwner) IsGuest() bool {
+	return o.UserID == uuid.Nil
 }
 
 // Interfaces
 type CartRepository interface {
 	FindBytesUserID(ctx context.Context, userID uuid.UUID) (*Cart, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*Cart, error)
 	Create(ctx context.Context, cart *Cart) error
 	AddItem(ctx context.Context, item *CartItem) error
 	UpdateItem(ctx context.Context, item *CartItem) error
 	RemoveItem(ctx context.Context, itemID uuid.UUID) error
 	ClearCart(ctx context.Context, cartID uuid.UUID) error
+	// MergeCart sets the target cart's quantity of each line in quantities, then deletes
+	// the guest cart and its items, in one transaction
+	MergeCart(ctx context.Context, guestCartID, targetCartID uuid.UUID, quantities map[CartLine]int) error
+	// DeleteGuestCarts deletes guest carts created before the time with their items, returns how many
+	DeleteGuestCarts(ctx context.Context, createdBefore time.Time) (int64, error)
 }
 
 type AddToCartRequest struct {
-	ProductID uuid.UUID `json:"product_id" validate:"required"`
-	Quantity  int       `json:"quantity" validate:"required,min=1"`
+	ProductID uuid.UUID  `json:"product_id" validate:"required"`
+	VariantID *uuid.UUID `json:"variant_id"` // Required for products with variants
+	Quantity  int        `json:"quantity" validate:"required,min=1"`
 }
 
 type UpdateCartItemRequest struct {
@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerGroup Entity
+// Users in a group (e.g. resellers) see the group's prices in the catalog, their cart and at checkout.
+// Users without a group pay the product's base price.
+type CustomerGroup struct {
+	ID          uuid.UUID        `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string           `json:"name" gorm:"unique;not null"`
+	Description string           `json:"description"`
+	PriceRules  []GroupPriceRule `json:"price_rules,omitempty" gorm:"foreignKey:GroupID"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// GroupPriceRule Entity
+// Targets either a product or a category. A product rule sets a fixed price or a percentage
+// discount, a category rule a percentage discount for every product in the category.
+type GroupPriceRule struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	GroupID         uuid.UUID  `json:"group_id" gorm:"type:uuid;not null;uniqueIndex:idx_group_price_rules_product;uniqueIndex:idx_group_price_rules_category"`
+	ProductID       *uuid.UUID `json:"product_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_group_price_rules_product"`
+	CategoryID      *uuid.UUID `json:"category_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_group_price_rules_category"`
+	Price           *float64   `json:"price,omitempty"`
+	DiscountPercent *float64   `json:"discount_percent,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// PriceList resolves product prices for one customer group. A nil PriceList prices everything at base price.
+type PriceList struct {
+	products   map[uuid.UUID]GroupPriceRule
+	categories map[uuid.UUID]GroupPriceRule
+}
+
+func NewPriceList(rules []GroupPriceRule) *PriceList {
+	l := &PriceList{
+		products:   make(map[uuid.UUID]GroupPriceRule),
+		categories: make(map[uuid.UUID]GroupPriceRule),
+	}
+	for _, rule := range rules {
+		switch {
+		case rule.ProductID != nil:
+			l.products[*rule.ProductID] = rule
+		case rule.CategoryID != nil:
+			l.categories[*rule.CategoryID] = rule
+		}
+	}
+	return l
+}
+
+// Price is what the group pays for the product. The product's own rule wins over its category's.
+func (l *PriceList) Price(product *Product) float64 {
+	return l.price(product, product.Price)
+}
+
+// VariantPrice is what the group pays for the variant. The product's rules apply to every variant,
+// discounts are taken off the variant's own price.
+func (l *PriceList) VariantPrice(product *Product, variant *ProductVariant) float64 {
+	return l.price(product, variant.BasePrice(product))
+}
+
+// Apply sets EffectivePrice on each product and its loaded variants
+func (l *PriceList) Apply(products ...*Product) {
+	for _, product := range products {
+		product.EffectivePrice = l.Price(product)
+		for i := range product.Variants {
+			product.Variants[i].EffectivePrice = l.VariantPrice(product, &product.Variants[i])
+		}
+	}
+}
+
+func (l *PriceList) price(product *Product, base float64) float64 {
+	if l == nil {
+		return base
+	}
+	if rule, ok := l.products[product.ID]; ok {
+		return rule.apply(base)
+	}
+	if rule, ok := l.categories[product.CategoryID]; ok {
+		return rule.apply(base)
+	}
+	return base
+}
+
+func (r GroupPriceRule) apply(base float64) float64 {
+	if r.Price != nil {
+		return *r.Price
+	}
+	if r.DiscountPercent != nil {
+		// Rounded to cents like the prices admins enter
+		return math.Round(base*(100-*r.DiscountPercent)) / 100
+	}
+	return base
+}
+
+// Repository Interface
+type CustomerGroupRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*CustomerGroup, error) // Preloads PriceRules
+	FindByName(ctx context.Context, name string) (*CustomerGroup, error)
+	FindAll(ctx context.Context) ([]CustomerGroup, error)
+	Create(ctx context.Context, group *CustomerGroup) error
+	Update(ctx context.Context, group *CustomerGroup) error // Doesn't touch PriceRules
+	Delete(ctx context.Context, id uuid.UUID) error         // Deletes its price rules too
+	FindPriceRules(ctx context.Context, groupID uuid.UUID) ([]GroupPriceRule, error)
+	// SavePriceRule replaces the group's rule for the same product or category
+	SavePriceRule(ctx context.Context, rule *GroupPriceRule) error
+	DeletePriceRule(ctx context.Context, groupID, ruleID uuid.UUID) error
+}
+
+// CustomerGroupService manages groups and their prices through the admin API
+type CustomerGroupService interface {
+	ListGroups(ctx context.Context) ([]CustomerGroup, error)
+	GetGroup(ctx context.Context, id uuid.UUID) (*CustomerGroup, error)
+	CreateGroup(ctx context.Context, req CustomerGroupRequest) (*CustomerGroup, error)
+	UpdateGroup(ctx context.Context, id uuid.UUID, req CustomerGroupRequest) (*CustomerGroup, error)
+	// DeleteGroup refuses groups that still have users
+	DeleteGroup(ctx context.Context, id uuid.UUID) error
+	SetPriceRule(ctx context.Context, groupID uuid.UUID, req PriceRuleRequest) (*CustomerGroup, error)
+	DeletePriceRule(ctx context.Context, groupID, ruleID uuid.UUID) (*CustomerGroup, error)
+	// AssignUser moves the user into the group, a nil groupID removes them from their group
+	AssignUser(ctx context.Context, userID uuid.UUID, groupID *uuid.UUID) (*User, error)
+}
+
+// PricingService resolves the prices a user pays
+type PricingService interface {
+	// PriceListFor returns the price list of the user's customer group, nil for guests (uuid.Nil) and users without one
+	PriceListFor(ctx context.Context, userID uuid.UUID) (*PriceList, error)
+	// ApplyPrices sets EffectivePrice on the products for the user
+	ApplyPrices(ctx context.Context, userID uuid.UUID, products ...*Product) error
+}
+
+// DTOs
+type CustomerGroupRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// PriceRuleRequest targets a product or a category, and sets a price (products only) or a discount
+type PriceRuleRequest struct {
+	ProductID       *uuid.UUID `json:"product_id"`
+	CategoryID      *uuid.UUID `json:"category_id"`
+	Price           *float64   `json:"price"`
+	DiscountPercent *float64   `json:"discount_percent"`
+}
+
+type AssignCustomerGroupRequest struct {
+	GroupID *uuid.UUID `json:"group_id"` // null removes the user from their group
+}
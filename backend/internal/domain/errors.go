@@ -8,4 +8,38 @@ var (
 	ErrConflict            = errors.New("your item already exists")
 	ErrBadParamInput       = errors.New("given param is not valid")
 	ErrUnauthorized        = errors.New("unauthorized")
+	ErrForbidden           = errors.New("you don't have permission to access this resource")
+	ErrWrongPassword       = errors.New("password is incorrect")
+	ErrInvalidToken        = errors.New("token is invalid or has expired")
+	ErrTooManyRequests     = errors.New("too many requests, please try again later")
+	ErrAlreadyVerified     = errors.New("email is already verified")
+	ErrEmailNotVerified    = errors.New("email address has not been verified")
+	ErrInvalidCode         = errors.New("two-factor code is invalid")
+	ErrTwoFactorRequired   = errors.New("two-factor authentication is required for this account")
+	ErrUnknownProvider     = errors.New("unknown login provider")
+	ErrInvalidScope        = errors.New("scope is unknown or not granted to you")
+	ErrAccountDisabled     = errors.New("this account has been disabled")
+	ErrCannotModifySelf    = errors.New("you can't change the role of or disable your own account")
+	ErrRoleInUse           = errors.New("role is still assigned to users")
+	ErrBuiltInRole         = errors.New("built-in roles can't be renamed or deleted, and the admin role always has every permission")
+	ErrLastAdmin           = errors.New("can't remove the last active admin")
+	ErrUnknownPermission   = errors.New("permission is not in the catalog")
+	ErrEmailNotLinkable    = errors.New("provider did not verify the email address, cannot link it to an existing account")
+	ErrCannotImpersonate   = errors.New("you can't impersonate yourself or users with permissions you don't have")
+	ErrImpersonating       = errors.New("this action is not allowed while impersonating a user")
+	ErrInvalidCSRFToken    = errors.New("missing or invalid CSRF token")
+	ErrNoShop              = errors.New("you don't have a shop, apply for one first")
+	ErrShopNotApproved     = errors.New("your shop hasn't been approved yet")
+	ErrShopNotPending      = errors.New("shop has already been reviewed")
+	ErrShopNotSuspendable  = errors.New("only approved shops can be suspended")
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrGroupInUse          = errors.New("customer group still has users")
+	ErrVariantRequired     = errors.New("choose a variant of this product")
+	ErrInvalidVariant      = errors.New("a variant needs exactly one value for each option of the product")
+	ErrProductHasVariants  = errors.New("options can't be changed while the product has variants")
+	ErrReauthRequired      = errors.New("sign in again to confirm this action")
+	ErrFileTooLarge        = errors.New("file is too large")
+	ErrUnsupportedFileType = errors.New("unsupported file type, upload a JPEG, PNG, GIF or WebP image")
+	ErrInvalidImageOrder   = errors.New("image_ids must list each of the product's images exactly once")
+	ErrInvalidPriceRule    = errors.New("a price rule needs either product_id or category_id, and either price (products only) or discount_percent between 0 and 100")
 )
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity Entity
+// Links an account at an external OpenID Connect provider to a local user.
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	Provider  string    `json:"provider" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject   string    `json:"-" gorm:"not null;uniqueIndex:idx_user_identities_provider_subject"` // The provider's "sub" claim
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UserIdentityRepository interface {
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*UserIdentity, error)
+	Create(ctx context.Context, identity *UserIdentity) error
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string) error
+}
+
+// ExternalIdentity is what a provider asserts about the user after a successful login
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCAuthRequest is the per-login state kept by the client between the redirect and the callback
+type OIDCAuthRequest struct {
+	Provider     string
+	State        string
+	Nonce        string
+	CodeVerifier string // PKCE
+}
+
+// OIDCProvider talks to a single OpenID Connect provider
+type OIDCProvider interface {
+	Name() string
+	// AuthCodeURL builds the authorization endpoint URL to redirect the browser to
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the authorization code and returns the validated ID token claims
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+type OIDCService interface {
+	// Begin returns the URL to redirect to and the state the callback must present
+	Begin(ctx context.Context, provider string) (string, *OIDCAuthRequest, error)
+	Complete(ctx context.Context, req *OIDCAuthRequest, state, code string, client ClientInfo) (*LoginResult, error)
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LoginAttempt tracks failed logins for one key (an account or an IP address)
+type LoginAttempt struct {
+	Failures    int
+	WindowStart time.Time
+	LockedUntil time.Time
+}
+
+// LoginAttemptStore persists LoginAttempt counters. The in-memory implementation
+// works for a single instance; a shared store (e.g. Redis) can be plugged in for more.
+// Increment, Decrement and Lock are read-modify-writes that concurrent logins race on, so
+// they must be atomic: the memory store holds its mutex, a shared store has to use INCR or
+// a Lua script instead of a Get followed by a write.
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string) (*LoginAttempt, error) // Returns nil when there is no record
+	// Increment adds a failure and returns the updated counter. A window that ended more than
+	// window ago, and isn't locked, starts over. The record lives at least window from now.
+	Increment(ctx context.Context, key string, window time.Duration) (LoginAttempt, error)
+	Decrement(ctx context.Context, key string) error // Never goes below zero, no-op without a record
+	// Lock sets LockedUntil unless it's already later and keeps the record for at least ttl
+	Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// LoginThrottler decides when login attempts must be delayed or rejected
+type LoginThrottler interface {
+	// Check returns a *LockedError if the account or IP is locked out. Otherwise it holds one of
+	// the attempts left before the lockout until RegisterFailure, RegisterSuccess or Release,
+	// so concurrent requests can't all get past it before the first failure is counted.
+	Check(ctx context.Context, email, ip string) error
+	RegisterFailure(ctx context.Context, email, ip string) error
+	RegisterSuccess(ctx context.Context, email, ip string) error
+	Release(ctx context.Context, email, ip string) error // Neither a failure nor a success (e.g. a database error)
+	Unlock(ctx context.Context, email string) error
+}
+
+// LockedError is returned while an account or IP is temporarily locked
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// MailMessage is a plain text email
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional emails (Port). See infrastructure.NewMailer for implementations.
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
@@ -39,6 +39,11 @@ type OrderItem struct {
 	Price     float64   `json:"price" gorm:"not null"` // Snapshot of product price
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Snapshot of the variant bought, kept when the variant is deleted later
+	VariantID    *uuid.UUID `json:"variant_id,omitempty" gorm:"type:uuid"`
+	SKU          string     `json:"sku,omitempty"`
+	VariantTitle string     `json:"variant_title,omitempty"`
 }
 
 // Interfaces
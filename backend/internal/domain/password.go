@@ -0,0 +1,17 @@
+package domain
+
+// PasswordPolicy decides which new passwords are acceptable
+type PasswordPolicy interface {
+	// Validate returns a *PasswordPolicyError for a weak password. personal holds the
+	// user's name and email, passwords built from them are rejected.
+	Validate(password string, personal ...string) error
+}
+
+// PasswordPolicyError explains why a password was rejected
+type PasswordPolicyError struct {
+	Reason string // e.g. "is too common"
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password " + e.Reason
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDataExport is the personal data held about a user, served as one JSON file per entity
+type UserDataExport struct {
+	Profile   *User
+	Addresses []Address
+	Wishlist  []Wishlist
+	Cart      *Cart // Nil when the user never added anything
+	Orders    []Order
+}
+
+// AccountErasureRepository removes personal data in one transaction.
+// Orders and their items are kept for accounting and keep pointing at the anonymized user.
+// The audit log is not erased: it is append-only security evidence, retained for the period
+// the operator documents (see README). Its events only point at the anonymized user by ID,
+// but keep the IP address and user agent, and failed logins keep the email that was tried.
+type AccountErasureRepository interface {
+	Anonymize(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
+
+// PrivacyService implements data export and account deletion requests
+type PrivacyService interface {
+	ExportData(ctx context.Context, userID uuid.UUID) (*UserDataExport, error)
+	// DeleteAccount anonymizes the account after confirming the password (and 2FA code when enabled).
+	// Accounts without a password need 2FA or a session started within the last few minutes.
+	DeleteAccount(ctx context.Context, userID, sessionID uuid.UUID, req DeleteAccountRequest) error
+}
+
+// DTOs
+type DeleteAccountRequest struct {
+	Password string `json:"password"` // Required unless the account only signs in through a provider
+	Code     string `json:"code"`     // Required when 2FA is enabled
+}
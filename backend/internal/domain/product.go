@@ -27,8 +27,19 @@ type Product struct {
 	ImageURL    string    `json:"image_url"`
 	CategoryID  uuid.UUID `json:"category_id" gorm:"type:uuid;not null"`
 	Category    Category  `json:"category" gorm:"foreignKey:CategoryID"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// Nil for the store's own products, set for products a seller lists in marketplace mode
+	ShopID    *uuid.UUID `json:"shop_id" gorm:"type:uuid;index"`
+	Shop      *Shop      `json:"shop,omitempty" gorm:"foreignKey:ShopID"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Only loaded for a single product, not in listings
+	Options  []ProductOption  `json:"options,omitempty" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Variants []ProductVariant `json:"variants,omitempty" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	Images   []ProductImage   `json:"images,omitempty" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+
+	// Price for the requesting user's customer group, only set where prices were resolved
+	EffectivePrice float64 `json:"effective_price,omitempty" gorm:"-"`
 }
 
 // Payload structs for Requests
@@ -40,7 +51,7 @@ type CreateProductRequest struct {
 	Name        string    `json:"name" validate:"required"`
 	Description string    `json:"description"`
 	Price       float64   `json:"price" validate:"required,min=0.01"`
-	Stock       int       `json:"stock" validate:"required,min=0"`
+	Stock       int       `json:"stock" validate:"min=0"`
 	CategoryID  uuid.UUID `json:"category_id" validate:"required"`
 	ImageURL    string    `json:"image_url"`
 }
@@ -56,11 +67,16 @@ type CategoryRepository interface {
 
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) error
+	// FindAll, FindBySlug and FindListedByID only see listed products:
+	// the store's own and those of approved shops
 	FindAll(ctx context.Context, params ProductQueryParams) ([]Product, int64, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*Product, error)
+	FindListedByID(ctx context.Context, id uuid.UUID) (*Product, error)
 	FindBySlug(ctx context.Context, slug string) (*Product, error)
 	Update(ctx context.Context, product *Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// DeleteInShop only deletes the product if it belongs to the shop, ErrNotFound otherwise
+	DeleteInShop(ctx context.Context, shopID, id uuid.UUID) error
 }
 
 type ProductQueryParams struct {
@@ -68,5 +84,6 @@ type ProductQueryParams struct {
 	Limit      int
 	Search     string
 	CategoryID string
+	ShopID     string
 	SortBy     string
 }
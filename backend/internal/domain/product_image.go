@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductImage Entity
+// An uploaded image in a product's gallery. The first one is the product's cover, copied to Product.ImageURL.
+type ProductImage struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ProductID   uuid.UUID `json:"product_id" gorm:"type:uuid;not null;index"`
+	URL         string    `json:"url" gorm:"not null"`
+	StorageKey  string    `json:"-" gorm:"not null"` // Key of the file in Storage
+	AltText     string    `json:"alt_text"`
+	Position    int       `json:"position" gorm:"not null;default:0"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"` // In bytes
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ImageContentTypes are the accepted image formats and the file extension they're stored with
+var ImageContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Repository Interface
+type ProductImageRepository interface {
+	FindByProductID(ctx context.Context, productID uuid.UUID) ([]ProductImage, error) // Ordered by position
+	FindByID(ctx context.Context, productID, id uuid.UUID) (*ProductImage, error)     // ErrNotFound if it isn't the product's
+	// Create, Delete and Reorder also copy the first image's URL to the product's ImageURL, in the same transaction
+	Create(ctx context.Context, image *ProductImage) error
+	Update(ctx context.Context, image *ProductImage) error
+	Delete(ctx context.Context, productID, id uuid.UUID) error
+	Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) error // Position follows the order of ids
+}
+
+// DTOs
+type UpdateProductImageRequest struct {
+	AltText string `json:"alt_text"` // Up to 255 characters
+}
+
+// ReorderProductImagesRequest lists all of the product's image IDs in their new order
+type ReorderProductImagesRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids" validate:"required"`
+}
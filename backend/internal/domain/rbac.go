@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,12 +9,14 @@ import (
 
 // Role Entity
 type Role struct {
-	ID          uuid.UUID    `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name        string       `json:"name" gorm:"unique;not null"`
-	Description string       `json:"description"`
-	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name        string    `json:"name" gorm:"unique;not null"`
+	Description string    `json:"description"`
+	// RequireTwoFactor blocks permission-guarded routes until the user has signed in with 2FA
+	RequireTwoFactor bool         `json:"require_two_factor" gorm:"default:false"`
+	Permissions      []Permission `json:"permissions" gorm:"many2many:role_permissions;"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
 }
 
 // Permission Entity
@@ -24,3 +27,114 @@ type Permission struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// Role names seeded by cmd/seed
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Permission names checked by middleware.RequirePermission, also used as API key scopes
+const (
+	PermissionProductCreate       = "product:create"
+	PermissionProductUpdate       = "product:update"
+	PermissionProductDelete       = "product:delete"
+	PermissionCategoryCreate      = "category:create"
+	PermissionCategoryUpdate      = "category:update"
+	PermissionCategoryDelete      = "category:delete"
+	PermissionOrderReadAll        = "order:read_all"
+	PermissionUserUnlock          = "user:unlock"
+	PermissionUserRead            = "user:read"
+	PermissionUserManage          = "user:manage"
+	PermissionUserImpersonate     = "user:impersonate"
+	PermissionAPIKeyManage        = "api_key:manage"
+	PermissionRoleManage          = "role:manage"
+	PermissionAuditRead           = "audit:read"
+	PermissionShopReview          = "shop:review"
+	PermissionCustomerGroupManage = "customer_group:manage"
+)
+
+// PermissionCatalog is the full list of permissions known to the application.
+// It's synced to the permissions table at startup and the admin role is granted all of it.
+var PermissionCatalog = []Permission{
+	{Name: PermissionProductCreate, Description: "Create products"},
+	{Name: PermissionProductUpdate, Description: "Update products"},
+	{Name: PermissionProductDelete, Description: "Delete products"},
+	{Name: PermissionCategoryCreate, Description: "Create categories"},
+	{Name: PermissionCategoryUpdate, Description: "Update categories"},
+	{Name: PermissionCategoryDelete, Description: "Delete categories"},
+	{Name: PermissionOrderReadAll, Description: "View orders of every customer"},
+	{Name: PermissionUserUnlock, Description: "Unlock accounts locked after failed logins"},
+	{Name: PermissionUserRead, Description: "Search users and view their orders and addresses"},
+	{Name: PermissionUserManage, Description: "Change user roles and disable accounts"},
+	{Name: PermissionUserImpersonate, Description: "Act as a customer for support, checkout and account changes stay blocked"},
+	{Name: PermissionAPIKeyManage, Description: "Create and revoke API keys"},
+	{Name: PermissionRoleManage, Description: "Create roles and change their permissions"},
+	{Name: PermissionAuditRead, Description: "View the security audit log"},
+	{Name: PermissionShopReview, Description: "Approve or reject seller shops"},
+	{Name: PermissionCustomerGroupManage, Description: "Manage customer groups and their prices, and assign users to them"},
+}
+
+// IsBuiltIn reports whether the role is one the application relies on, those can't be renamed or deleted
+func (r *Role) IsBuiltIn() bool {
+	return r.Name == RoleAdmin || r.Name == RoleUser
+}
+
+// Repository Interface
+type RoleRepository interface {
+	FindByID(ctx context.Context, id uuid.UUID) (*Role, error) // Preloads Permissions
+	FindByName(ctx context.Context, name string) (*Role, error)
+	FindAll(ctx context.Context) ([]Role, error) // Preloads Permissions
+	Create(ctx context.Context, role *Role) error
+	Update(ctx context.Context, role *Role) error // Doesn't touch Permissions
+	Delete(ctx context.Context, id uuid.UUID) error
+	AddPermissions(ctx context.Context, roleID uuid.UUID, permissions []Permission) error
+	RemovePermission(ctx context.Context, roleID uuid.UUID, permission Permission) error
+}
+
+type PermissionRepository interface {
+	FindAll(ctx context.Context) ([]Permission, error)
+	FindByNames(ctx context.Context, names []string) ([]Permission, error)
+	// SyncCatalog inserts missing permissions, updates descriptions, deletes permissions
+	// that are no longer in the catalog and grants the whole catalog to the admin role
+	SyncCatalog(ctx context.Context, catalog []Permission) error
+}
+
+// RBACService resolves role permissions (cached)
+type RBACService interface {
+	GetPermissions(ctx context.Context, roleID uuid.UUID) (map[string]struct{}, error)
+	HasPermission(ctx context.Context, roleID uuid.UUID, permission string) (bool, error)
+	RequiresTwoFactor(ctx context.Context, roleID uuid.UUID) (bool, error)
+	InvalidateRole(roleID uuid.UUID)
+}
+
+// RoleService manages roles and their permissions through the admin API
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]Role, error)
+	GetRole(ctx context.Context, id uuid.UUID) (*Role, error)
+	CreateRole(ctx context.Context, req CreateRoleRequest) (*Role, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, req UpdateRoleRequest) (*Role, error)
+	// DeleteRole refuses built-in roles and roles that are still assigned to users
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	AttachPermissions(ctx context.Context, roleID uuid.UUID, names []string) (*Role, error)
+	DetachPermission(ctx context.Context, roleID uuid.UUID, name string) (*Role, error)
+}
+
+// DTOs
+type CreateRoleRequest struct {
+	Name             string   `json:"name" validate:"required"`
+	Description      string   `json:"description"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+	Permissions      []string `json:"permissions"`
+}
+
+type UpdateRoleRequest struct {
+	Name             string `json:"name" validate:"required"`
+	Description      string `json:"description"`
+	RequireTwoFactor bool   `json:"require_two_factor"`
+}
+
+type AttachPermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session Entity
+// A session is created on login and shares its ID with the refresh token family.
+// Access tokens carry the session ID in the "sid" claim so they can be revoked.
+type Session struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	// TwoFactorVerified is set when the session was started (or confirmed) with a second factor
+	TwoFactorVerified bool      `json:"two_factor_verified" gorm:"default:false"`
+	Current           bool      `json:"current" gorm:"-"` // Set when listing, true for the requesting session
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ClientInfo describes the device a request came from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Session, error)
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) // Returns the revoked session IDs
+	UpdateLastSeen(ctx context.Context, id uuid.UUID, ipAddress string, at time.Time) error
+	ExtendExpiry(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+	MarkTwoFactorVerified(ctx context.Context, id uuid.UUID) error
+}
+
+// RevocationStore answers "is this session revoked?" and "is this user disabled?" for every authenticated request
+type RevocationStore interface {
+	IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	MarkRevoked(sessionIDs ...uuid.UUID)
+	IsUserDisabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	MarkUserDisabled(userID uuid.UUID, disabled bool) // Called by DisableUser and EnableUser
+	Touch(ctx context.Context, sessionID uuid.UUID, ipAddress string)
+}
+
+type SessionService interface {
+	CreateSession(ctx context.Context, userID uuid.UUID, client ClientInfo, expiresAt time.Time, twoFactorVerified bool) (*Session, error)
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*Session, error)
+	MarkTwoFactorVerified(ctx context.Context, sessionID uuid.UUID) error
+	ExtendSession(ctx context.Context, sessionID uuid.UUID, expiresAt time.Time) error
+	ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error // Also used after password changes
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Shop statuses. A shop starts pending and can only sell once an admin approved it.
+// An approved shop can be suspended, which takes its products off the catalog until it's approved again.
+const (
+	ShopStatusPending   = "pending"
+	ShopStatusApproved  = "approved"
+	ShopStatusRejected  = "rejected"
+	ShopStatusSuspended = "suspended"
+)
+
+// Shop Entity
+// A seller's storefront in marketplace mode, each user owns at most one.
+// Products without a shop belong to the store itself and are managed by admins.
+type Shop struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	OwnerID     uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Owner       *User     `json:"owner,omitempty" gorm:"foreignKey:OwnerID"`
+	Name        string    `json:"name" gorm:"not null"`
+	Slug        string    `json:"slug" gorm:"unique;not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Status      string    `json:"status" gorm:"not null;default:pending;index"`
+	// Set when an admin approved, rejected or suspended the shop
+	ReviewedByID    *uuid.UUID `json:"reviewed_by_id,omitempty" gorm:"type:uuid"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// IsApproved reports whether the shop may list products
+func (s *Shop) IsApproved() bool {
+	return s.Status == ShopStatusApproved
+}
+
+// Repository Interface
+type ShopRepository interface {
+	Create(ctx context.Context, shop *Shop) error
+	FindByID(ctx context.Context, id uuid.UUID) (*Shop, error)
+	FindBySlug(ctx context.Context, slug string) (*Shop, error)
+	FindByOwnerID(ctx context.Context, ownerID uuid.UUID) (*Shop, error)
+	FindAll(ctx context.Context, params ShopQueryParams) ([]Shop, int64, error) // Preloads Owner
+	Update(ctx context.Context, shop *Shop) error
+}
+
+type ShopQueryParams struct {
+	Page   int
+	Limit  int
+	Status string // Empty for every status
+}
+
+// ShopService covers sellers running their shop, the public shop pages and admin review
+type ShopService interface {
+	// Apply opens a pending shop for the user, who can only have one
+	Apply(ctx context.Context, ownerID uuid.UUID, req CreateShopRequest) (*Shop, error)
+	GetMyShop(ctx context.Context, ownerID uuid.UUID) (*Shop, error)
+	// UpdateMyShop changes name and description, a rejected shop goes back to pending for another review
+	UpdateMyShop(ctx context.Context, ownerID uuid.UUID, req UpdateShopRequest) (*Shop, error)
+	// GetPublicShop returns approved shops only, others are ErrNotFound
+	GetPublicShop(ctx context.Context, slug string) (*Shop, error)
+	ListShops(ctx context.Context, params ShopQueryParams) ([]Shop, int64, error)
+	GetShop(ctx context.Context, id uuid.UUID) (*Shop, error)
+	// ApproveShop acts on pending and suspended shops, RejectShop only on pending ones
+	ApproveShop(ctx context.Context, reviewerID, shopID uuid.UUID) (*Shop, error)
+	RejectShop(ctx context.Context, reviewerID, shopID uuid.UUID, req RejectShopRequest) (*Shop, error)
+	// SuspendShop closes an approved shop, its products leave the catalog
+	SuspendShop(ctx context.Context, reviewerID, shopID uuid.UUID, req RejectShopRequest) (*Shop, error)
+}
+
+// DTOs
+type CreateShopRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateShopRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// RejectShopRequest is also used to suspend a shop, the reason is shown to its owner
+type RejectShopRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+// Storage keeps uploaded files (Port). See infrastructure.NewStorage for implementations.
+type Storage interface {
+	// Put stores the file under key, replacing any file with the same key, and returns its public URL
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Delete removes the file, a key that doesn't exist is not an error
+	Delete(ctx context.Context, key string) error
+}
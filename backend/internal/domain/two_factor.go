@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode Entity
+// One-time backup codes for when the authenticator app is unavailable. Stored hashed.
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RecoveryCodeRepository interface {
+	ReplaceAll(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	// Consume marks an unused code as used. Returns false if no such code exists.
+	Consume(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	DeleteAll(ctx context.Context, userID uuid.UUID) error
+}
+
+// TwoFactorSetup is returned when enrollment starts
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type TwoFactorService interface {
+	// Setup generates a new secret. 2FA stays disabled until Enable confirms a code.
+	Setup(ctx context.Context, userID uuid.UUID) (*TwoFactorSetup, error)
+	// Enable confirms the secret and returns freshly generated recovery codes
+	Enable(ctx context.Context, userID, sessionID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userID uuid.UUID, req DisableTwoFactorRequest) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	// VerifyCode accepts a TOTP code or an unused recovery code
+	VerifyCode(ctx context.Context, user *User, code string) (bool, error)
+}
+
+// DTOs
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"` // Required unless the account only signs in through a provider
+	Code     string `json:"code" validate:"required"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"` // From /auth/login, the OIDC callback sets a cookie instead
+	Code           string `json:"code" validate:"required"`
+}
@@ -9,25 +9,149 @@ import (
 
 // User Entity
 type User struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email" gorm:"unique;not null"`
-	Password  string    `json:"-"`
-	RoleID    *uuid.UUID `json:"role_id" gorm:"type:uuid"`
-	Role      Role       `json:"role" gorm:"foreignKey:RoleID"`
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email" gorm:"unique;not null"`
+	Password   string     `json:"-"`
+	RoleID     *uuid.UUID `json:"role_id" gorm:"type:uuid"`
+	Role       *Role      `json:"role,omitempty" gorm:"foreignKey:RoleID"`
+	VerifiedAt *time.Time `json:"verified_at"` // Nil until the email address is confirmed
+	DisabledAt *time.Time `json:"disabled_at"` // Set by an admin, disabled accounts can't sign in
+	// Set when the user deleted their account, personal fields are overwritten but orders are kept
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+
+	// TOTP two-factor authentication
+	TwoFactorEnabled bool   `json:"two_factor_enabled" gorm:"default:false"`
+	TOTPSecret       string `json:"-"`
+	TOTPLastStep     int64  `json:"-"` // Last accepted time step, rejects replayed codes
+
+	// Decides the prices the user pays, nil for base prices
+	CustomerGroupID *uuid.UUID     `json:"customer_group_id" gorm:"type:uuid;index"`
+	CustomerGroup   *CustomerGroup `json:"customer_group,omitempty" gorm:"foreignKey:CustomerGroupID"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserProfile is the current user as returned by /auth/me
+type UserProfile struct {
+	*User
+	Permissions []string `json:"permissions"`
+}
+
+// LoginResult is returned by UserService.Login.
+// When TwoFactorRequired is set, Tokens is nil and the client must exchange
+// ChallengeToken plus a TOTP code via CompleteTwoFactorLogin.
+type LoginResult struct {
+	User              *User
+	Tokens            *TokenPair
+	TwoFactorRequired bool
+	ChallengeToken    string
+	// TwoFactorSetupRequired is set when the role's policy requires 2FA but the user hasn't enrolled
+	TwoFactorSetupRequired bool
+	// AccountClaimed is set when an external login took over an account with an unverified email,
+	// its password, 2FA and sessions were revoked
+	AccountClaimed bool
+}
+
 // UserRepository interface (Port)
 type UserRepository interface {
-	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*User, error) // Preloads Role and its Permissions
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Create(ctx context.Context, user *User) error
+	// Each update writes only its own columns, so concurrent changes to other fields aren't reverted
+	UpdateName(ctx context.Context, id uuid.UUID, name string) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error
+	MarkVerified(ctx context.Context, id uuid.UUID, at time.Time) error
+	UpdateTwoFactor(ctx context.Context, id uuid.UUID, secret string, enabled bool) error
+	// UpdateTOTPLastStep only moves forward. Returns false if step was already used.
+	UpdateTOTPLastStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	FindAll(ctx context.Context, params UserQueryParams) ([]User, int64, error) // Preloads Role
+	UpdateRole(ctx context.Context, id uuid.UUID, roleID *uuid.UUID) error
+	// AssignRoleWhereMissing gives roleID to every user without a role, returns how many were updated
+	AssignRoleWhereMissing(ctx context.Context, roleID uuid.UUID) (int64, error)
+	SetDisabledAt(ctx context.Context, id uuid.UUID, at *time.Time) error
+	CountByRole(ctx context.Context, roleID uuid.UUID) (int64, error)
+	CountActiveByRole(ctx context.Context, roleID uuid.UUID) (int64, error) // Excludes disabled users
+	UpdateCustomerGroup(ctx context.Context, id uuid.UUID, groupID *uuid.UUID) error
+	CountByCustomerGroup(ctx context.Context, groupID uuid.UUID) (int64, error)
+}
+
+type UserQueryParams struct {
+	Page   int
+	Limit  int
+	Search string // Matches name or email
+	RoleID string
+	Status string // "active" or "disabled", empty for both
+}
+
+// User statuses accepted by UserQueryParams.Status
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+// AdminUserService is the user management used by the admin API
+type AdminUserService interface {
+	ListUsers(ctx context.Context, params UserQueryParams) ([]User, int64, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*User, error)
+	// ChangeRole assigns a role and revokes the user's sessions so tokens carrying the old role stop working
+	ChangeRole(ctx context.Context, actorID, userID uuid.UUID, req ChangeRoleRequest) (*User, error)
+	// DisableUser blocks sign in and revokes every session of the user
+	DisableUser(ctx context.Context, actorID, userID uuid.UUID) (*User, error)
+	EnableUser(ctx context.Context, userID uuid.UUID) (*User, error)
+	// Impersonate issues a short-lived access token acting as the user, for customer support.
+	// Only users whose permissions are a subset of the impersonator's can be impersonated.
+	Impersonate(ctx context.Context, impersonator Impersonator, userID uuid.UUID) (*Impersonation, error)
+}
+
+// Impersonator is the signed-in support user asking to act as someone else
+type Impersonator struct {
+	UserID    uuid.UUID
+	RoleID    uuid.UUID
+	SessionID uuid.UUID
+}
+
+// Impersonation is the token returned to the support user, it's never set as a cookie
+// so the support user's own sign in stays as it is
+type Impersonation struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
 }
 
 // UserService interface (Use Case)
 type UserService interface {
-	Register(ctx context.Context, name, email, password string) error
-	Login(ctx context.Context, email, password string) (string, error) // Returns JWT token
+	Register(ctx context.Context, name, email, password string) (*User, error)
+	Login(ctx context.Context, email, password string, client ClientInfo) (*LoginResult, error)
+	CompleteTwoFactorLogin(ctx context.Context, req VerifyTwoFactorRequest, client ClientInfo) (*LoginResult, error)
+	// LoginWithIdentity signs in the user linked to an external identity, linking or creating one if needed
+	LoginWithIdentity(ctx context.Context, identity ExternalIdentity, client ClientInfo) (*LoginResult, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout ends the session of the refresh token and returns its user, uuid.Nil if there was none
+	Logout(ctx context.Context, refreshToken string) (uuid.UUID, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*UserProfile, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req UpdateProfileRequest) (*UserProfile, error)
+	// ChangePassword revokes every session and returns fresh tokens for the calling device
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, req ChangePasswordRequest, client ClientInfo) (*TokenPair, error)
+	// RequestPasswordReset emails a reset link. Unknown emails are silently ignored.
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req ResetPasswordRequest) error
+	UnlockAccount(ctx context.Context, userID uuid.UUID) error // Clears a brute-force lockout
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, userID uuid.UUID) error
+}
+
+// DTOs
+type UpdateProfileRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type ChangeRoleRequest struct {
+	RoleID uuid.UUID `json:"role_id" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,password"`
 }
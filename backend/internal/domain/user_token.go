@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserToken purposes
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken Entity
+// Single-use token sent to the user by email. Only the SHA-256 hash is stored.
+type UserToken struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose   string     `json:"purpose" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *UserToken) error
+	FindByHash(ctx context.Context, purpose, hash string) (*UserToken, error)
+	// MarkUsed consumes the token. Returns false if it was already used.
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	// InvalidateAll consumes every outstanding token of the purpose for the user
+	InvalidateAll(ctx context.Context, userID uuid.UUID, purpose string) error
+	FindLatest(ctx context.Context, userID uuid.UUID, purpose string) (*UserToken, error)
+}
+
+// DTOs
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,password"` // At least 8 characters with a letter and a digit
+}
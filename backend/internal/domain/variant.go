@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductOption Entity
+// An option type of a product, e.g. Size or Color. Each variant has one value per option.
+type ProductOption struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ProductID uuid.UUID `json:"product_id" gorm:"type:uuid;not null;uniqueIndex:idx_product_options_name"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_product_options_name"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductVariant Entity
+// A sellable combination of option values with its own SKU and stock. Price overrides the product's price when set.
+// A product with variants is only sold by variant, and its Stock is the sum of theirs.
+type ProductVariant struct {
+	ID        uuid.UUID            `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ProductID uuid.UUID            `json:"product_id" gorm:"type:uuid;not null;index"`
+	SKU       string               `json:"sku" gorm:"unique;not null"`
+	Title     string               `json:"title"` // Option values in option order, e.g. "M / Red"
+	Price     *float64             `json:"price" gorm:"check:price > 0"`
+	Stock     int                  `json:"stock" gorm:"not null;check:stock >= 0"`
+	ImageURL  string               `json:"image_url"`
+	Options   []VariantOptionValue `json:"options" gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+
+	// Price for the requesting user's customer group, only set where prices were resolved
+	EffectivePrice float64 `json:"effective_price,omitempty" gorm:"-"`
+}
+
+// VariantOptionValue Entity, the variant's value for one of the product's options
+type VariantOptionValue struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	VariantID uuid.UUID `json:"variant_id" gorm:"type:uuid;not null;uniqueIndex:idx_variant_option_values_option"`
+	OptionID  uuid.UUID `json:"option_id" gorm:"type:uuid;not null;uniqueIndex:idx_variant_option_values_option"`
+	Value     string    `json:"value" gorm:"not null"`
+}
+
+// BasePrice is the variant's price before customer group pricing
+func (v *ProductVariant) BasePrice(product *Product) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+// VariantTitle joins the values in the order of the options, e.g. "M / Red"
+func VariantTitle(options []ProductOption, values map[uuid.UUID]string) string {
+	parts := make([]string, 0, len(options))
+	for _, option := range options {
+		parts = append(parts, values[option.ID])
+	}
+	return strings.Join(parts, " / ")
+}
+
+// Repository Interface
+type VariantRepository interface {
+	FindOptions(ctx context.Context, productID uuid.UUID) ([]ProductOption, error) // Ordered by position
+	CreateOption(ctx context.Context, option *ProductOption) error
+	DeleteOption(ctx context.Context, productID, optionID uuid.UUID) error
+	FindByProductID(ctx context.Context, productID uuid.UUID) ([]ProductVariant, error)
+	FindByID(ctx context.Context, productID, id uuid.UUID) (*ProductVariant, error) // ErrNotFound if it isn't the product's
+	FindBySKU(ctx context.Context, sku string) (*ProductVariant, error)
+	// Create, Update and Delete also set the product's stock to the sum of its variants', in the same transaction
+	Create(ctx context.Context, variant *ProductVariant) error
+	Update(ctx context.Context, variant *ProductVariant) error // Doesn't touch Options
+	Delete(ctx context.Context, productID, id uuid.UUID) error
+}
+
+// DTOs
+type CreateProductOptionRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type CreateVariantRequest struct {
+	SKU      string            `json:"sku" validate:"required"`
+	Price    *float64          `json:"price" validate:"omitempty,min=0.01"` // Optional, defaults to the product's price
+	Stock    int               `json:"stock" validate:"min=0"`
+	ImageURL string            `json:"image_url"`
+	Options  map[string]string `json:"options" validate:"required"` // Option name to value, e.g. {"Size": "M", "Color": "Red"}
+}
+
+// UpdateVariantRequest replaces the variant's SKU, price, stock and image. Its option values can't change.
+type UpdateVariantRequest struct {
+	SKU      string   `json:"sku" validate:"required"`
+	Price    *float64 `json:"price" validate:"omitempty,min=0.01"` // null uses the product's price
+	Stock    int      `json:"stock" validate:"min=0"`
+	ImageURL string   `json:"image_url"`
+}
@@ -25,19 +25,15 @@ func NewAddressHandler(service service.AddressService) *AddressHandler {
 // @Param request body domain.CreateAddressRequest true "Create Address Request"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /addresses [post]
 func (h *AddressHandler) Create(c *fiber.Ctx) error {
 	user := c.Locals("user").(*utils.JWTClaims)
 
 	var req domain.CreateAddressRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
-	}
-
-	// Validate (Simple check)
-	if req.RecipientName == "" || req.Street == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Recipient Name and Street are required"})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
 	err := h.service.Create(c.Context(), user.UserID, req)
@@ -79,6 +75,7 @@ func (h *AddressHandler) GetMyAddresses(c *fiber.Ctx) error {
 // @Failure 400 {object} map[string]interface{}
 // @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /addresses/{id} [put]
 func (h *AddressHandler) Update(c *fiber.Ctx) error {
@@ -91,8 +88,8 @@ func (h *AddressHandler) Update(c *fiber.Ctx) error {
 	}
 
 	var req domain.UpdateAddressRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
 	err = h.service.Update(c.Context(), user.UserID, addressID, req)
@@ -0,0 +1,413 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/internal/service"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type AdminUserHandler struct {
+	userService          domain.UserService
+	adminUserService     domain.AdminUserService
+	orderService         service.OrderService
+	addressService       service.AddressService
+	customerGroupService domain.CustomerGroupService
+	auditLogger          domain.AuditLogger
+}
+
+func NewAdminUserHandler(userService domain.UserService, adminUserService domain.AdminUserService, orderService service.OrderService, addressService service.AddressService, customerGroupService domain.CustomerGroupService, auditLogger domain.AuditLogger) *AdminUserHandler {
+	return &AdminUserHandler{
+		userService:          userService,
+		adminUserService:     adminUserService,
+		orderService:         orderService,
+		addressService:       addressService,
+		customerGroupService: customerGroupService,
+		auditLogger:          auditLogger,
+	}
+}
+
+// FindAll godoc
+// @Summary Search users
+// @Description Get a paginated list of users, optionally filtered by name/email, role and status (Admin only)
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param search query string false "Matches name or email"
+// @Param role_id query string false "Role ID"
+// @Param status query string false "active or disabled"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users [get]
+func (h *AdminUserHandler) FindAll(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	params := domain.UserQueryParams{
+		Page:   page,
+		Limit:  limit,
+		Search: c.Query("search"),
+		RoleID: c.Query("role_id"),
+		Status: c.Query("status"),
+	}
+
+	users, total, err := h.adminUserService.ListUsers(c.Context(), params)
+	if err != nil {
+		if err == domain.ErrBadParamInput {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid role_id or status"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": users,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// FindByID godoc
+// @Summary Get user
+// @Description Get a user with their role and permissions (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id} [get]
+func (h *AdminUserHandler) FindByID(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	user, err := h.adminUserService.GetUser(c.Context(), userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": user})
+}
+
+// GetOrders godoc
+// @Summary Get user's orders
+// @Description Retrieve the order history of a user (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/orders [get]
+func (h *AdminUserHandler) GetOrders(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	if _, err := h.adminUserService.GetUser(c.Context(), userID); err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	orders, err := h.orderService.GetMyOrders(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"data": orders})
+}
+
+// GetAddresses godoc
+// @Summary Get user's addresses
+// @Description Retrieve the saved addresses of a user (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/addresses [get]
+func (h *AdminUserHandler) GetAddresses(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	if _, err := h.adminUserService.GetUser(c.Context(), userID); err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	addresses, err := h.addressService.GetMyAddresses(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"data": addresses})
+}
+
+// ChangeRole godoc
+// @Summary Change user's role
+// @Description Assign a role to a user. Their sessions are revoked so the new role applies on next sign in. (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body domain.ChangeRoleRequest true "Change Role Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/role [put]
+func (h *AdminUserHandler) ChangeRole(c *fiber.Ctx) error {
+	actor := c.Locals("user").(*utils.JWTClaims)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	var req domain.ChangeRoleRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.adminUserService.GetUser(c.Context(), userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	user, err := h.adminUserService.ChangeRole(c.Context(), actor.UserID, userID, req)
+	if err != nil {
+		if err == domain.ErrBadParamInput {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Role not found"})
+		}
+		return userErrorResponse(c, err)
+	}
+	h.recordUserChange(c, domain.AuditActionUserRoleChanged, before, user)
+
+	return c.JSON(fiber.Map{"message": "Role updated", "data": user})
+}
+
+// ChangeCustomerGroup godoc
+// @Summary Change user's customer group
+// @Description Move a user into a customer group so they see its prices, or out of their group with a null group_id (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body domain.AssignCustomerGroupRequest true "Assign Customer Group Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/customer-group [put]
+func (h *AdminUserHandler) ChangeCustomerGroup(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	var req domain.AssignCustomerGroupRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.adminUserService.GetUser(c.Context(), userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	if req.GroupID != nil {
+		if _, err := h.customerGroupService.GetGroup(c.Context(), *req.GroupID); err != nil {
+			if err == domain.ErrNotFound {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Customer group not found"})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	user, err := h.customerGroupService.AssignUser(c.Context(), userID, req.GroupID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+	h.recordUserChange(c, domain.AuditActionUserGroupChanged, before, user)
+
+	return c.JSON(fiber.Map{"message": "Customer group updated", "data": user})
+}
+
+// Disable godoc
+// @Summary Disable user account
+// @Description Block the user from signing in and revoke all of their sessions (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/disable [post]
+func (h *AdminUserHandler) Disable(c *fiber.Ctx) error {
+	actor := c.Locals("user").(*utils.JWTClaims)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	before, err := h.adminUserService.GetUser(c.Context(), userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	user, err := h.adminUserService.DisableUser(c.Context(), actor.UserID, userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+	h.recordUserChange(c, domain.AuditActionUserDisabled, before, user)
+
+	return c.JSON(fiber.Map{"message": "Account disabled", "data": user})
+}
+
+// Enable godoc
+// @Summary Re-enable user account
+// @Description Allow a disabled user to sign in again (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/enable [post]
+func (h *AdminUserHandler) Enable(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	before, err := h.adminUserService.GetUser(c.Context(), userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	user, err := h.adminUserService.EnableUser(c.Context(), userID)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+	h.recordUserChange(c, domain.AuditActionUserEnabled, before, user)
+
+	return c.JSON(fiber.Map{"message": "Account enabled", "data": user})
+}
+
+// Unlock godoc
+// @Summary Unlock user account
+// @Description Clear the failed login lockout of an account (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/unlock [post]
+func (h *AdminUserHandler) Unlock(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	if err := h.userService.UnlockAccount(c.Context(), userID); err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	h.auditLogger.Record(c.Context(), auditEntry(c, domain.AuditActionUserUnlocked, domain.AuditTargetUser, userID.String()))
+
+	return c.JSON(fiber.Map{"message": "Account unlocked"})
+}
+
+// Impersonate godoc
+// @Summary Impersonate user
+// @Description Get a short-lived bearer token acting as the user, to see what they see when helping them.
+// @Description Requests made with it are audited, checkout and account changes are refused. The token
+// @Description is only returned in the body, the caller stays signed in as themselves. (Support only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/users/{id}/impersonate [post]
+func (h *AdminUserHandler) Impersonate(c *fiber.Ctx) error {
+	actor := c.Locals("user").(*utils.JWTClaims)
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid User ID"})
+	}
+
+	impersonation, err := h.adminUserService.Impersonate(c.Context(), domain.Impersonator{
+		UserID:    actor.UserID,
+		RoleID:    actor.RoleID,
+		SessionID: actor.SessionID,
+	}, userID)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		case domain.ErrCannotImpersonate, domain.ErrAccountDisabled:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	entry := auditEntry(c, domain.AuditActionUserImpersonated, domain.AuditTargetUser, userID.String())
+	entry.Metadata = map[string]string{"expires_at": impersonation.ExpiresAt.UTC().Format(time.RFC3339)}
+	h.auditLogger.Record(c.Context(), entry)
+
+	return c.JSON(fiber.Map{"data": impersonation})
+}
+
+// recordUserChange audits an admin change to a user with the fields it changed
+func (h *AdminUserHandler) recordUserChange(c *fiber.Ctx, action string, before, after *domain.User) {
+	entry := auditEntry(c, action, domain.AuditTargetUser, after.ID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func userErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	case domain.ErrCannotModifySelf, domain.ErrLastAdmin:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
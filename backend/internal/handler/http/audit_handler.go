@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type AuditHandler struct {
+	auditLogger domain.AuditLogger
+}
+
+func NewAuditHandler(auditLogger domain.AuditLogger) *AuditHandler {
+	return &AuditHandler{auditLogger: auditLogger}
+}
+
+// FindAll godoc
+// @Summary Query audit log
+// @Description Get a paginated list of audit events, newest first (Admin only)
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param actor_id query string false "User who performed the action"
+// @Param action query string false "e.g. auth.login_failed or product.updated"
+// @Param target_type query string false "e.g. user, role, product"
+// @Param target_id query string false "Target ID (the email for failed logins)"
+// @Param from query string false "Start of the time range, RFC 3339, inclusive"
+// @Param to query string false "End of the time range, RFC 3339, exclusive"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/audit-events [get]
+func (h *AuditHandler) FindAll(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	params := domain.AuditQueryParams{
+		Page:       page,
+		Limit:      limit,
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+	var err error
+	if params.From, err = timeQuery(c, "from"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid from, use RFC 3339 (e.g. 2024-01-31T00:00:00Z)"})
+	}
+	if params.To, err = timeQuery(c, "to"); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid to, use RFC 3339 (e.g. 2024-01-31T00:00:00Z)"})
+	}
+
+	events, total, err := h.auditLogger.FindEvents(c.Context(), params)
+	if err != nil {
+		if err == domain.ErrBadParamInput {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid actor_id or time range"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": events,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// timeQuery parses an optional RFC 3339 query parameter
+func timeQuery(c *fiber.Ctx, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// auditEntry starts an audit entry with the signed-in actor (if any) and the client of the request
+func auditEntry(c *fiber.Ctx, action, targetType, targetID string) domain.AuditEntry {
+	entry := domain.AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Client:     clientInfo(c),
+	}
+	if actor, ok := c.Locals("user").(*utils.JWTClaims); ok {
+		entry.ActorID = actor.UserID
+		entry.APIKeyID = actor.APIKeyID
+		if actor.Impersonating() {
+			// The support user did it, not the customer they were acting as
+			entry.ActorID = actor.Actor.UserID
+			entry.Metadata = map[string]string{"impersonated_user_id": actor.UserID.String()}
+		}
+	}
+	return entry
+}
@@ -1,35 +1,40 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
 	"github.com/user/go-ecommerce/internal/domain"
 	"github.com/user/go-ecommerce/internal/service"
 	"github.com/user/go-ecommerce/pkg/utils"
 )
 
+const (
+	guestCartCookie     = "guest_cart"
+	guestCartCookiePath = "/api" // Sent to the cart and to login/register, which merge it
+)
+
 type CartHandler struct {
 	service service.CartService
+	cfg     *config.Config
 }
 
-func NewCartHandler(service service.CartService) *CartHandler {
-	return &CartHandler{service: service}
+func NewCartHandler(service service.CartService, cfg *config.Config) *CartHandler {
+	return &CartHandler{service: service, cfg: cfg}
 }
 
 // GetCart godoc
 // @Summary Get user cart
-// @Description Retrieve the current user's shopping cart
+// @Description Retrieve the current user's shopping cart. Without a login, the guest cart from the guest_cart cookie.
 // @Tags cart
 // @Produce json
 // @Success 200 {object} domain.Cart
 // @Failure 500 {object} map[string]interface{}
 // @Router /cart [get]
 func (h *CartHandler) GetCart(c *fiber.Ctx) error {
-	user := c.Locals("user").(*utils.JWTClaims)
-	// UserID in JWTClaims is already uuid.UUID, no need to parse
-	userID := user.UserID
-
-	cart, err := h.service.GetCart(c.Context(), userID)
+	cart, err := h.service.GetCart(c.Context(), h.cartOwner(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -39,7 +44,8 @@ func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 
 // AddToCart godoc
 // @Summary Add item to cart
-// @Description Add a product to the user's cart
+// @Description Add a product to the user's cart. Guests get a cart identified by the signed guest_cart cookie,
+// @Description it is merged into their own cart when they log in or register.
 // @Tags cart
 // @Accept json
 // @Produce json
@@ -47,27 +53,39 @@ func (h *CartHandler) GetCart(c *fiber.Ctx) error {
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /cart [post]
 func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
-	user := c.Locals("user").(*utils.JWTClaims)
-	userID := user.UserID
+	owner := h.cartOwner(c)
 
 	var req domain.AddToCartRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
-	if err := h.service.AddToCart(c.Context(), userID, req); err != nil {
-		if err.Error() == "insufficient stock" {
+	cart, err := h.service.AddToCart(c.Context(), owner, req)
+	if err != nil {
+		if err == domain.ErrInsufficientStock {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Insufficient stock"})
 		}
+		if err == domain.ErrVariantRequired {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
 		if err == domain.ErrNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product or variant not found"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	if owner.IsGuest() && cart.ID != owner.GuestCartID {
+		token, err := utils.GenerateGuestCartToken(cart.ID, h.cfg)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Cookie(newCookie(h.cfg, guestCartCookie, token, guestCartCookiePath, time.Now().Add(utils.GuestCartTTL(h.cfg))))
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Item added to cart"})
 }
 
@@ -81,12 +99,11 @@ func (h *CartHandler) AddToCart(c *fiber.Ctx) error {
 // @Param request body domain.UpdateCartItemRequest true "Update Cart Item Request"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /cart/items/{id} [put]
 func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
-	user := c.Locals("user").(*utils.JWTClaims)
-	userID := user.UserID
-
 	itemIDStr := c.Params("id")
 	itemID, err := uuid.Parse(itemIDStr)
 	if err != nil {
@@ -94,11 +111,17 @@ func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
 	}
 
 	var req domain.UpdateCartItemRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
-	if err := h.service.UpdateItem(c.Context(), userID, itemID, req); err != nil {
+	if err := h.service.UpdateItem(c.Context(), h.cartOwner(c), itemID, req); err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cart item not found"})
+		}
+		if err == domain.ErrInsufficientStock {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Insufficient stock"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -113,21 +136,48 @@ func (h *CartHandler) UpdateItem(c *fiber.Ctx) error {
 // @Param id path string true "Item ID"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /cart/items/{id} [delete]
 func (h *CartHandler) RemoveItem(c *fiber.Ctx) error {
-	user := c.Locals("user").(*utils.JWTClaims)
-	userID := user.UserID
-
 	itemIDStr := c.Params("id")
 	itemID, err := uuid.Parse(itemIDStr)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
 	}
 
-	if err := h.service.RemoveItem(c.Context(), userID, itemID); err != nil {
+	if err := h.service.RemoveItem(c.Context(), h.cartOwner(c), itemID); err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Cart item not found"})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Item removed from cart"})
 }
+
+// cartOwner is the signed-in user, or the guest cart from the cookie for anonymous requests
+func (h *CartHandler) cartOwner(c *fiber.Ctx) domain.CartOwner {
+	if user, ok := c.Locals("user").(*utils.JWTClaims); ok {
+		return domain.CartOwner{UserID: user.UserID}
+	}
+	guestCartID, _ := guestCartFromCookie(c, h.cfg)
+	return domain.CartOwner{GuestCartID: guestCartID}
+}
+
+// guestCartFromCookie returns the guest cart ID if the request has a valid guest cart cookie
+func guestCartFromCookie(c *fiber.Ctx, cfg *config.Config) (uuid.UUID, bool) {
+	token := c.Cookies(guestCartCookie)
+	if token == "" {
+		return uuid.Nil, false
+	}
+	cartID, err := utils.ValidateGuestCartToken(token, cfg)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return cartID, true
+}
+
+func clearGuestCartCookie(c *fiber.Ctx, cfg *config.Config) {
+	c.Cookie(newCookie(cfg, guestCartCookie, "", guestCartCookiePath, time.Now().Add(-1*time.Hour)))
+}
@@ -24,12 +24,14 @@ func NewCategoryHandler(service service.CategoryService) *CategoryHandler {
 // @Param request body domain.CreateCategoryRequest true "Create Category Request"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /categories [post]
 func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 	var req domain.CreateCategoryRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
 	if err := h.service.Create(c.Context(), req); err != nil {
@@ -92,7 +94,9 @@ func (h *CategoryHandler) FindByID(c *fiber.Ctx) error {
 // @Param request body domain.CreateCategoryRequest true "Update Category Request"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /categories/{id} [put]
 func (h *CategoryHandler) Update(c *fiber.Ctx) error {
@@ -103,8 +107,8 @@ func (h *CategoryHandler) Update(c *fiber.Ctx) error {
 	}
 
 	var req domain.CreateCategoryRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
 	if err := h.service.Update(c.Context(), id, req); err != nil {
@@ -123,6 +127,7 @@ func (h *CategoryHandler) Update(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path string true "Category ID"
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /categories/{id} [delete]
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type CustomerGroupHandler struct {
+	groupService domain.CustomerGroupService
+	auditLogger  domain.AuditLogger
+}
+
+func NewCustomerGroupHandler(groupService domain.CustomerGroupService, auditLogger domain.AuditLogger) *CustomerGroupHandler {
+	return &CustomerGroupHandler{groupService: groupService, auditLogger: auditLogger}
+}
+
+// FindAll godoc
+// @Summary List customer groups
+// @Description List all customer groups (Admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups [get]
+func (h *CustomerGroupHandler) FindAll(c *fiber.Ctx) error {
+	groups, err := h.groupService.ListGroups(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"data": groups})
+}
+
+// FindByID godoc
+// @Summary Get customer group
+// @Description Get a customer group with its price rules (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Customer Group ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups/{id} [get]
+func (h *CustomerGroupHandler) FindByID(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Customer Group ID"})
+	}
+
+	group, err := h.groupService.GetGroup(c.Context(), groupID)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": group})
+}
+
+// Create godoc
+// @Summary Create customer group
+// @Description Create a customer group, e.g. resellers or employees (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body domain.CustomerGroupRequest true "Customer Group Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups [post]
+func (h *CustomerGroupHandler) Create(c *fiber.Ctx) error {
+	var req domain.CustomerGroupRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	group, err := h.groupService.CreateGroup(c.Context(), req)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+	h.recordGroupChange(c, domain.AuditActionCustomerGroupCreated, group.ID, nil, group)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Customer group created successfully", "data": group})
+}
+
+// Update godoc
+// @Summary Update customer group
+// @Description Rename a customer group or change its description (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer Group ID"
+// @Param request body domain.CustomerGroupRequest true "Customer Group Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups/{id} [put]
+func (h *CustomerGroupHandler) Update(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Customer Group ID"})
+	}
+
+	var req domain.CustomerGroupRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.groupService.GetGroup(c.Context(), groupID)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+
+	group, err := h.groupService.UpdateGroup(c.Context(), groupID, req)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+	h.recordGroupChange(c, domain.AuditActionCustomerGroupUpdated, groupID, before, group)
+
+	return c.JSON(fiber.Map{"message": "Customer group updated successfully", "data": group})
+}
+
+// Delete godoc
+// @Summary Delete customer group
+// @Description Delete a customer group and its prices. Groups that still have users can't be deleted. (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Customer Group ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups/{id} [delete]
+func (h *CustomerGroupHandler) Delete(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Customer Group ID"})
+	}
+
+	before, err := h.groupService.GetGroup(c.Context(), groupID)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+
+	if err := h.groupService.DeleteGroup(c.Context(), groupID); err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+	h.recordGroupChange(c, domain.AuditActionCustomerGroupDeleted, groupID, before, nil)
+
+	return c.JSON(fiber.Map{"message": "Customer group deleted successfully"})
+}
+
+// SetPrice godoc
+// @Summary Set group price
+// @Description Set the group's fixed price or percentage discount for a product, or its discount for a whole category.
+// @Description Replaces the group's existing rule for the same product or category. A product's own rule wins over its category's. (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer Group ID"
+// @Param request body domain.PriceRuleRequest true "Price Rule Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups/{id}/prices [post]
+func (h *CustomerGroupHandler) SetPrice(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Customer Group ID"})
+	}
+
+	var req domain.PriceRuleRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.groupService.GetGroup(c.Context(), groupID)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+
+	group, err := h.groupService.SetPriceRule(c.Context(), groupID, req)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Product or category not found"})
+		}
+		return customerGroupErrorResponse(c, err)
+	}
+	h.recordGroupChange(c, domain.AuditActionCustomerGroupPricesChanged, groupID, before, group)
+
+	return c.JSON(fiber.Map{"message": "Price saved", "data": group})
+}
+
+// DeletePrice godoc
+// @Summary Delete group price
+// @Description Remove a price rule, the group pays the base price (or its category's price) again (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Customer Group ID"
+// @Param ruleId path string true "Price Rule ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/customer-groups/{id}/prices/{ruleId} [delete]
+func (h *CustomerGroupHandler) DeletePrice(c *fiber.Ctx) error {
+	groupID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Customer Group ID"})
+	}
+	ruleID, err := uuid.Parse(c.Params("ruleId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Price Rule ID"})
+	}
+
+	before, err := h.groupService.GetGroup(c.Context(), groupID)
+	if err != nil {
+		return customerGroupErrorResponse(c, err)
+	}
+
+	group, err := h.groupService.DeletePriceRule(c.Context(), groupID, ruleID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Price rule not found"})
+		}
+		return customerGroupErrorResponse(c, err)
+	}
+	h.recordGroupChange(c, domain.AuditActionCustomerGroupPricesChanged, groupID, before, group)
+
+	return c.JSON(fiber.Map{"message": "Price removed", "data": group})
+}
+
+// recordGroupChange audits a change to a group or its prices, before is nil for creations and after for deletions
+func (h *CustomerGroupHandler) recordGroupChange(c *fiber.Ctx, action string, groupID uuid.UUID, before, after *domain.CustomerGroup) {
+	entry := auditEntry(c, action, domain.AuditTargetCustomerGroup, groupID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func customerGroupErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Customer group not found"})
+	case domain.ErrBadParamInput:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name can't be blank"})
+	case domain.ErrInvalidPriceRule:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case domain.ErrConflict:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A customer group with this name already exists"})
+	case domain.ErrGroupInUse:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
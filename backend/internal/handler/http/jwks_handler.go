@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type JWKSHandler struct{}
+
+func NewJWKSHandler() *JWKSHandler {
+	return &JWKSHandler{}
+}
+
+// Get godoc
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying the API's JWTs. Empty when tokens are signed with HS256.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) Get(c *fiber.Ctx) error {
+	// Short enough that verifiers notice a newly scheduled key well before it signs anything
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(utils.CurrentJWKS())
+}
@@ -4,41 +4,114 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/config"
 	"github.com/user/go-ecommerce/internal/domain"
 	"github.com/user/go-ecommerce/pkg/utils"
 )
 
-func AuthMiddleware(cfg *config.Config) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		var tokenString string
-
-		// 1. Try Cookie
-		cookieToken := c.Cookies("token")
-		if cookieToken != "" {
-			tokenString = cookieToken
-		}
+// apiKeyHeader carries an API key as an alternative to the JWT cookie/bearer token
+const apiKeyHeader = "X-API-Key"
 
-		// 2. Fallback to Header (if no cookie)
-		if tokenString == "" {
-			authHeader := c.Get("Authorization")
-			if authHeader != "" {
-				parts := strings.Split(authHeader, " ")
-				if len(parts) == 2 && parts[0] == "Bearer" {
-					tokenString = parts[1]
-				}
+// AuthMiddleware authenticates the request with a JWT. When apiKeyService is not nil,
+// the route also accepts an API key; pair it with RequirePermission, which checks the key's scopes.
+func AuthMiddleware(cfg *config.Config, revocationStore domain.RevocationStore, apiKeyService domain.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if apiKeyService != nil {
+			if plainKey := c.Get(apiKeyHeader); plainKey != "" {
+				return authenticateAPIKey(c, apiKeyService, plainKey)
 			}
 		}
 
+		tokenString := tokenFromRequest(c)
 		if tokenString == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": domain.ErrUnauthorized.Error()})
 		}
 		claims, err := utils.ValidateToken(tokenString, cfg)
-		if err != nil {
+		if err != nil || claims.SessionID == uuid.Nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
 		}
 
+		// Tokens from before roles were assigned on registration, signing in again assigns the default role
+		if cfg.Auth.RBACEnforced && claims.RoleID == uuid.Nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Token has no role, please sign in again"})
+		}
+
+		// 3. Check the session hasn't been revoked (logout, "log out everywhere", password change)
+		revoked, err := revocationStore.IsRevoked(c.Context(), claims.SessionID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": domain.ErrInternalServerError.Error()})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Session has been revoked"})
+		}
+
+		// 4. Check the account hasn't been disabled. Sessions are revoked when it is, but an
+		// impersonation token runs on the support user's session, not the target's.
+		disabled, err := revocationStore.IsUserDisabled(c.Context(), claims.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": domain.ErrInternalServerError.Error()})
+		}
+		if disabled {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": domain.ErrAccountDisabled.Error()})
+		}
+		revocationStore.Touch(c.Context(), claims.SessionID, c.IP())
+
 		c.Locals("user", claims)
 		return c.Next()
 	}
 }
+
+// OptionalAuthMiddleware authenticates like AuthMiddleware when the request carries a token and
+// lets it through without c.Locals("user") otherwise, for routes guests can use too (the cart).
+// An invalid or expired token is still rejected so the client knows to refresh it.
+func OptionalAuthMiddleware(cfg *config.Config, revocationStore domain.RevocationStore) fiber.Handler {
+	authenticate := AuthMiddleware(cfg, revocationStore, nil)
+	return func(c *fiber.Ctx) error {
+		if tokenFromRequest(c) == "" {
+			return c.Next()
+		}
+		return authenticate(c)
+	}
+}
+
+// tokenFromRequest reads the access token from the cookie, or the Authorization header without one
+func tokenFromRequest(c *fiber.Ctx) string {
+	// 1. Try Cookie
+	if cookieToken := c.Cookies("token"); cookieToken != "" {
+		return cookieToken
+	}
+
+	// 2. Fallback to Header (if no cookie)
+	authHeader := c.Get("Authorization")
+	if authHeader != "" {
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
+		}
+	}
+	return ""
+}
+
+func authenticateAPIKey(c *fiber.Ctx, apiKeyService domain.APIKeyService, plainKey string) error {
+	key, err := apiKeyService.Authenticate(c.Context(), plainKey)
+	if err != nil {
+		if err == domain.ErrUnauthorized {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid API key"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": domain.ErrInternalServerError.Error()})
+	}
+
+	// The key acts as its creator, RequirePermission also checks the creator's role still allows it
+	roleID := uuid.Nil
+	if key.CreatedBy.RoleID != nil {
+		roleID = *key.CreatedBy.RoleID
+	}
+	c.Locals("user", &utils.JWTClaims{
+		UserID:   key.CreatedByID,
+		RoleID:   roleID,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	})
+	return c.Next()
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+const (
+	// csrfCookie is set by the auth handler (login, refresh and GET /api/auth/csrf) and readable from JS
+	csrfCookie = "csrf_token"
+	// CSRFHeader must repeat the csrf_token cookie on state-changing requests authenticated by cookie
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// credentialCookies are the cookies that authenticate a request on their own,
+// the browser attaches them to cross-site requests as far as SameSite allows
+var credentialCookies = []string{"token", "refresh_token"}
+
+// CSRFProtection is registered app-wide and implements the double-submit cookie pattern:
+// a POST, PUT, PATCH or DELETE carrying an auth cookie must send the csrf_token cookie's
+// value in the X-CSRF-Token header. Another site can make the browser send the cookies,
+// but it can't read them to fill in the header.
+//
+// Requests without auth cookies (bearer tokens, API keys, guests) can't be forged this
+// way and pass untouched, as do paths under CSRF_EXEMPT_PATHS such as payment webhooks.
+func CSRFProtection(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !cfg.CSRF.Enabled || isSafeMethod(c.Method()) || !hasCredentialCookie(c) || isCSRFExempt(c.Path(), cfg.CSRF.ExemptPaths) {
+			return c.Next()
+		}
+
+		cookie := c.Cookies(csrfCookie)
+		header := c.Get(CSRFHeader)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": domain.ErrInvalidCSRFToken.Error()})
+		}
+		return c.Next()
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func hasCredentialCookie(c *fiber.Ctx) bool {
+	for _, name := range credentialCookies {
+		if c.Cookies(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isCSRFExempt matches path prefixes, "/api/webhooks" covers "/api/webhooks/stripe" but not "/api/webhooksfoo"
+func isCSRFExempt(path string, exempt []string) bool {
+	for _, prefix := range exempt {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/internal/config"
+)
+
+func TestCSRFProtection(t *testing.T) {
+	cfg := &config.Config{CSRF: config.CSRFConfig{Enabled: true, ExemptPaths: []string{"/api/webhooks/"}}}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		cookies string
+		header  string
+		want    int
+	}{
+		{name: "safe method", method: fiber.MethodGet, cookies: "token=jwt", want: fiber.StatusOK},
+		{name: "no auth cookie", method: fiber.MethodPost, want: fiber.StatusOK},
+		{name: "only the csrf cookie", method: fiber.MethodPost, cookies: "csrf_token=abc", want: fiber.StatusOK},
+		{name: "matching header", method: fiber.MethodPost, cookies: "token=jwt; csrf_token=abc", header: "abc", want: fiber.StatusOK},
+		{name: "refresh cookie counts", method: fiber.MethodPost, cookies: "refresh_token=opaque; csrf_token=abc", header: "abc", want: fiber.StatusOK},
+		{name: "missing header", method: fiber.MethodPost, cookies: "token=jwt; csrf_token=abc", want: fiber.StatusForbidden},
+		{name: "wrong header", method: fiber.MethodDelete, cookies: "token=jwt; csrf_token=abc", header: "abd", want: fiber.StatusForbidden},
+		{name: "missing cookie", method: fiber.MethodPut, cookies: "token=jwt", header: "abc", want: fiber.StatusForbidden},
+		{name: "empty cookie and header", method: fiber.MethodPatch, cookies: "refresh_token=opaque; csrf_token=", header: "", want: fiber.StatusForbidden},
+		{name: "exempt path", method: fiber.MethodPost, path: "/api/webhooks/stripe", cookies: "token=jwt", want: fiber.StatusOK},
+		{name: "exempt prefix only on a segment", method: fiber.MethodPost, path: "/api/webhooksfoo", cookies: "token=jwt", want: fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(CSRFProtection(cfg))
+			app.All("/*", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+			path := tt.path
+			if path == "" {
+				path = "/api/cart"
+			}
+			req := httptest.NewRequest(tt.method, path, nil)
+			if tt.cookies != "" {
+				req.Header.Set("Cookie", tt.cookies)
+			}
+			if tt.header != "" {
+				req.Header.Set(CSRFHeader, tt.header)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		app := fiber.New()
+		app.Use(CSRFProtection(&config.Config{}))
+		app.All("/*", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+		req := httptest.NewRequest(fiber.MethodPost, "/api/cart", nil)
+		req.Header.Set("Cookie", "token=jwt")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+		}
+	})
+}
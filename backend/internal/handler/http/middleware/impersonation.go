@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// impersonatedByHeader tells the client (and anything logging responses) who is acting as the user
+const impersonatedByHeader = "X-Impersonated-By"
+
+// AuditImpersonation is registered app-wide. It runs the request first, so it sees the
+// claims AuthMiddleware set further down the chain, then tags the response and records
+// every request made with an impersonation token.
+func AuditImpersonation(auditLogger domain.AuditLogger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		claims, ok := c.Locals("user").(*utils.JWTClaims)
+		if !ok || !claims.Impersonating() {
+			return err
+		}
+		c.Set(impersonatedByHeader, claims.Actor.UserID.String())
+
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		}
+		// Fiber reuses request buffers, copy what the entry keeps
+		auditLogger.Record(c.Context(), domain.AuditEntry{
+			ActorID:    claims.Actor.UserID,
+			Action:     domain.AuditActionImpersonatedRequest,
+			TargetType: domain.AuditTargetUser,
+			TargetID:   claims.UserID.String(),
+			Client:     domain.ClientInfo{UserAgent: strings.Clone(c.Get(fiber.HeaderUserAgent)), IPAddress: c.IP()},
+			Metadata: map[string]string{
+				"method": strings.Clone(c.Method()),
+				"path":   strings.Clone(c.Path()),
+				"status": strconv.Itoa(status),
+			},
+		})
+		return err
+	}
+}
+
+// RejectImpersonation must be registered after AuthMiddleware. It keeps support staff
+// acting as a customer away from actions that can't be undone or that change how the
+// customer signs in, such as checkout, password changes and account deletion.
+func RejectImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if claims, ok := c.Locals("user").(*utils.JWTClaims); ok && claims.Impersonating() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": domain.ErrImpersonating.Error()})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// RequirePermission must be registered after AuthMiddleware.
+// It rejects the request with 403 unless the user's role grants the permission,
+// and, when the role requires 2FA, unless the session was verified with a second factor.
+// API keys additionally need the permission in their scopes.
+func RequirePermission(rbacService domain.RBACService, permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(*utils.JWTClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": domain.ErrUnauthorized.Error()})
+		}
+
+		allowed, err := rbacService.HasPermission(c.Context(), claims.RoleID, permission)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": domain.ErrInternalServerError.Error()})
+		}
+		if !allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": domain.ErrForbidden.Error()})
+		}
+
+		if claims.APIKeyID != uuid.Nil {
+			// Keys aren't interactive, so the 2FA policy doesn't apply, scopes do
+			if !slices.Contains(claims.Scopes, permission) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": domain.ErrForbidden.Error()})
+			}
+			return c.Next()
+		}
+
+		if !claims.MFA {
+			required, err := rbacService.RequiresTwoFactor(c.Context(), claims.RoleID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": domain.ErrInternalServerError.Error()})
+			}
+			if required {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": domain.ErrTwoFactorRequired.Error()})
+			}
+		}
+
+		return c.Next()
+	}
+}
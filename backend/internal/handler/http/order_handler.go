@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/internal/domain"
 	"github.com/user/go-ecommerce/internal/service"
 	"github.com/user/go-ecommerce/pkg/utils"
 )
@@ -21,6 +22,7 @@ func NewOrderHandler(service service.OrderService) *OrderHandler {
 // @Produce json
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /orders/checkout [post]
 func (h *OrderHandler) Checkout(c *fiber.Ctx) error {
@@ -32,6 +34,9 @@ func (h *OrderHandler) Checkout(c *fiber.Ctx) error {
 		if err.Error() == "cart is empty" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Cart is empty"})
 		}
+		if err == domain.ErrEmailNotVerified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Please verify your email address before checking out"})
+		}
 		// Basic check for stock errors
 		// In production, better error typing is needed
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -69,6 +74,7 @@ func (h *OrderHandler) GetMyOrders(c *fiber.Ctx) error {
 // @Tags orders
 // @Produce json
 // @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /admin/orders [get]
 func (h *OrderHandler) GetAllOrders(c *fiber.Ctx) error {
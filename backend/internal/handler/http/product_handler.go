@@ -5,32 +5,37 @@ import (
 	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/domain"
 	"github.com/user/go-ecommerce/internal/service"
+	"github.com/user/go-ecommerce/pkg/utils"
 )
 
 type ProductHandler struct {
-	service service.ProductService
+	service        service.ProductService
+	pricingService domain.PricingService
+	auditLogger    domain.AuditLogger
 }
 
-func NewProductHandler(service service.ProductService) *ProductHandler {
-	return &ProductHandler{service: service}
+func NewProductHandler(service service.ProductService, pricingService domain.PricingService, auditLogger domain.AuditLogger) *ProductHandler {
+	return &ProductHandler{service: service, pricingService: pricingService, auditLogger: auditLogger}
 }
 
 func (h *ProductHandler) Create(c *fiber.Ctx) error {
 	var req domain.CreateProductRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
-	if err := h.service.Create(c.Context(), req); err != nil {
+	product, err := h.service.Create(c.Context(), req)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.recordProductChange(c, domain.AuditActionProductCreated, product.ID, nil, product)
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Product created successfully"})
 }
 
 // FindAll godoc
 // @Summary Get all products
-// @Description Get a list of products with pagination and filtering
+// @Description Get a list of products with pagination and filtering. effective_price is the price for the signed-in user's customer group.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -38,6 +43,7 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 // @Param limit query int false "Page size"
 // @Param search query string false "Search term"
 // @Param category_id query string false "Category ID"
+// @Param shop_id query string false "Shop ID, only that seller's products"
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /products [get]
@@ -46,18 +52,23 @@ func (h *ProductHandler) FindAll(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 10)
 	search := c.Query("search")
 	categoryID := c.Query("category_id")
+	shopID := c.Query("shop_id")
 
 	params := domain.ProductQueryParams{
 		Page:       page,
 		Limit:      limit,
 		Search:     search,
 		CategoryID: categoryID,
+		ShopID:     shopID,
 	}
 
 	products, total, err := h.service.FindAll(c.Context(), params)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := applyCustomerPrices(c, h.pricingService, products); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(fiber.Map{
 		"data":  products,
@@ -87,13 +98,16 @@ func (h *ProductHandler) FindByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
 	}
 
-	product, err := h.service.FindByID(c.Context(), id)
+	product, err := h.service.FindListedByID(c.Context(), id)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := h.pricingService.ApplyPrices(c.Context(), customerID(c), product); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(product)
 }
@@ -107,6 +121,9 @@ func (h *ProductHandler) FindBySlug(c *fiber.Ctx) error {
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err := h.pricingService.ApplyPrices(c.Context(), customerID(c), product); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	return c.JSON(product)
 }
@@ -119,8 +136,16 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 	}
 
 	var req domain.CreateProductRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.service.FindByID(c.Context(), id)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	if err := h.service.Update(c.Context(), id, req); err != nil {
@@ -130,6 +155,13 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// Reloaded so the category in the diff matches category_id
+	after, err := h.service.FindByID(c.Context(), id)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	h.recordProductChange(c, domain.AuditActionProductUpdated, id, before, after)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Product updated successfully"})
 }
 
@@ -140,12 +172,46 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
 	}
 
+	before, err := h.service.FindByID(c.Context(), id)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	if err := h.service.Delete(c.Context(), id); err != nil {
 		if err == domain.ErrNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
+	h.recordProductChange(c, domain.AuditActionProductDeleted, id, before, nil)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Product deleted successfully"})
 }
+
+// recordProductChange audits a product change, before is nil for creations and after for deletions
+func (h *ProductHandler) recordProductChange(c *fiber.Ctx, action string, productID uuid.UUID, before, after *domain.Product) {
+	entry := auditEntry(c, action, domain.AuditTargetProduct, productID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+// customerID is the signed-in user prices are resolved for, uuid.Nil for guests
+func customerID(c *fiber.Ctx) uuid.UUID {
+	if user, ok := c.Locals("user").(*utils.JWTClaims); ok {
+		return user.UserID
+	}
+	return uuid.Nil
+}
+
+// applyCustomerPrices sets EffectivePrice on a page of products for the signed-in user
+func applyCustomerPrices(c *fiber.Ctx, pricingService domain.PricingService, products []domain.Product) error {
+	pointers := make([]*domain.Product, len(products))
+	for i := range products {
+		pointers[i] = &products[i]
+	}
+	return pricingService.ApplyPrices(c.Context(), customerID(c), pointers...)
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/internal/service"
+)
+
+// ProductImageHandler serves a product's image gallery, for admins under /products
+// and for sellers under /seller/products after SellerHandler.RequireOwnProduct
+type ProductImageHandler struct {
+	service     service.ProductImageService
+	auditLogger domain.AuditLogger
+}
+
+func NewProductImageHandler(service service.ProductImageService, auditLogger domain.AuditLogger) *ProductImageHandler {
+	return &ProductImageHandler{service: service, auditLogger: auditLogger}
+}
+
+// FindAll godoc
+// @Summary List product images
+// @Description Get a product's image gallery in display order, the first image is the product's cover
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/images [get]
+func (h *ProductImageHandler) FindAll(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	images, err := h.service.FindAll(c.Context(), productID)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": images})
+}
+
+// Upload godoc
+// @Summary Upload product image
+// @Description Add a JPEG, PNG, GIF or WebP image to the end of a product's gallery. The type is detected from the file's content.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param image formData file true "Image file"
+// @Param alt_text formData string false "Alternative text"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Failure 415 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/images [post]
+func (h *ProductImageHandler) Upload(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Send the image as multipart/form-data in the image field"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer file.Close()
+	// The request body is capped by Fiber's BodyLimit, so this can't grow unbounded
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Form values point into Fiber's request buffer, which is reused after the handler returns
+	altText := strings.Clone(c.FormValue("alt_text"))
+
+	image, err := h.service.Upload(c.Context(), productID, data, altText)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+	h.recordImageChange(c, domain.AuditActionProductImageAdded, productID, nil, image)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Image uploaded successfully", "data": image})
+}
+
+// Update godoc
+// @Summary Update product image
+// @Description Change an image's alternative text
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Param request body domain.UpdateProductImageRequest true "Update Image Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/images/{imageId} [put]
+func (h *ProductImageHandler) Update(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+	imageID, err := uuid.Parse(c.Params("imageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Image ID"})
+	}
+
+	var req domain.UpdateProductImageRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.service.FindByID(c.Context(), productID, imageID)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+
+	image, err := h.service.UpdateAltText(c.Context(), productID, imageID, req)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+	h.recordImageChange(c, domain.AuditActionProductImageUpdated, productID, before, image)
+
+	return c.JSON(fiber.Map{"message": "Image updated successfully", "data": image})
+}
+
+// Reorder godoc
+// @Summary Reorder product images
+// @Description Set the order of a product's gallery by listing all of its image IDs, the first becomes the cover
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body domain.ReorderProductImagesRequest true "Reorder Images Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/images/order [put]
+func (h *ProductImageHandler) Reorder(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	var req domain.ReorderProductImagesRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.service.FindAll(c.Context(), productID)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+
+	images, err := h.service.Reorder(c.Context(), productID, req)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+	h.recordImageChange(c, domain.AuditActionProductImagesReordered, productID, before, images)
+
+	return c.JSON(fiber.Map{"message": "Images reordered", "data": images})
+}
+
+// Delete godoc
+// @Summary Delete product image
+// @Description Remove an image from a product's gallery and delete the stored file
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/images/{imageId} [delete]
+func (h *ProductImageHandler) Delete(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+	imageID, err := uuid.Parse(c.Params("imageId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Image ID"})
+	}
+
+	before, err := h.service.FindByID(c.Context(), productID, imageID)
+	if err != nil {
+		return productImageErrorResponse(c, err)
+	}
+
+	if err := h.service.Delete(c.Context(), productID, imageID); err != nil {
+		return productImageErrorResponse(c, err)
+	}
+	h.recordImageChange(c, domain.AuditActionProductImageDeleted, productID, before, nil)
+
+	return c.JSON(fiber.Map{"message": "Image deleted successfully"})
+}
+
+// recordImageChange audits a change to a product's gallery, the event targets the product
+func (h *ProductImageHandler) recordImageChange(c *fiber.Ctx, action string, productID uuid.UUID, before, after interface{}) {
+	entry := auditEntry(c, action, domain.AuditTargetProduct, productID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func productImageErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product or image not found"})
+	case domain.ErrBadParamInput:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "The image is empty or its alt text is longer than 255 characters"})
+	case domain.ErrInvalidImageOrder:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case domain.ErrFileTooLarge:
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+	case domain.ErrUnsupportedFileType:
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type RoleHandler struct {
+	roleService domain.RoleService
+	auditLogger domain.AuditLogger
+}
+
+func NewRoleHandler(roleService domain.RoleService, auditLogger domain.AuditLogger) *RoleHandler {
+	return &RoleHandler{roleService: roleService, auditLogger: auditLogger}
+}
+
+// FindAll godoc
+// @Summary List roles
+// @Description List all roles with their permissions (Admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles [get]
+func (h *RoleHandler) FindAll(c *fiber.Ctx) error {
+	roles, err := h.roleService.ListRoles(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"data": roles})
+}
+
+// FindByID godoc
+// @Summary Get role
+// @Description Get a role with its permissions (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) FindByID(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Role ID"})
+	}
+
+	role, err := h.roleService.GetRole(c.Context(), roleID)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": role})
+}
+
+// Create godoc
+// @Summary Create role
+// @Description Create a role, optionally with permissions from the catalog (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateRoleRequest true "Create Role Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles [post]
+func (h *RoleHandler) Create(c *fiber.Ctx) error {
+	var req domain.CreateRoleRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	role, err := h.roleService.CreateRole(c.Context(), req)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	h.recordRoleChange(c, domain.AuditActionRoleCreated, role.ID, nil, role)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Role created successfully", "data": role})
+}
+
+// Update godoc
+// @Summary Update role
+// @Description Update a role's name, description and 2FA policy. Built-in roles can't be renamed. (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body domain.UpdateRoleRequest true "Update Role Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) Update(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Role ID"})
+	}
+
+	var req domain.UpdateRoleRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.roleService.GetRole(c.Context(), roleID)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	role, err := h.roleService.UpdateRole(c.Context(), roleID, req)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	h.recordRoleChange(c, domain.AuditActionRoleUpdated, roleID, before, role)
+
+	return c.JSON(fiber.Map{"message": "Role updated successfully", "data": role})
+}
+
+// Delete godoc
+// @Summary Delete role
+// @Description Delete a role. Built-in roles and roles still assigned to users can't be deleted. (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) Delete(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Role ID"})
+	}
+
+	before, err := h.roleService.GetRole(c.Context(), roleID)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	if err := h.roleService.DeleteRole(c.Context(), roleID); err != nil {
+		return roleErrorResponse(c, err)
+	}
+	h.recordRoleChange(c, domain.AuditActionRoleDeleted, roleID, before, nil)
+
+	return c.JSON(fiber.Map{"message": "Role deleted successfully"})
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List the permission catalog (Admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *fiber.Ctx) error {
+	permissions, err := h.roleService.ListPermissions(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"data": permissions})
+}
+
+// AttachPermissions godoc
+// @Summary Attach permissions to role
+// @Description Grant permissions from the catalog to a role (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param request body domain.AttachPermissionsRequest true "Attach Permissions Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles/{id}/permissions [post]
+func (h *RoleHandler) AttachPermissions(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Role ID"})
+	}
+
+	var req domain.AttachPermissionsRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.roleService.GetRole(c.Context(), roleID)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	role, err := h.roleService.AttachPermissions(c.Context(), roleID, req.Permissions)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	h.recordRoleChange(c, domain.AuditActionRolePermissionsChanged, roleID, before, role)
+
+	return c.JSON(fiber.Map{"message": "Permissions attached", "data": role})
+}
+
+// DetachPermission godoc
+// @Summary Detach permission from role
+// @Description Remove a permission from a role (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param name path string true "Permission name, e.g. product:create"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/roles/{id}/permissions/{name} [delete]
+func (h *RoleHandler) DetachPermission(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Role ID"})
+	}
+
+	// Clients may escape the colon in names like product:create
+	name, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid permission name"})
+	}
+
+	before, err := h.roleService.GetRole(c.Context(), roleID)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	role, err := h.roleService.DetachPermission(c.Context(), roleID, name)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	h.recordRoleChange(c, domain.AuditActionRolePermissionsChanged, roleID, before, role)
+
+	return c.JSON(fiber.Map{"message": "Permission detached", "data": role})
+}
+
+// recordRoleChange audits a role change, before is nil for creations and after for deletions
+func (h *RoleHandler) recordRoleChange(c *fiber.Ctx, action string, roleID uuid.UUID, before, after *domain.Role) {
+	entry := auditEntry(c, action, domain.AuditTargetRole, roleID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func roleErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role or permission not found"})
+	case domain.ErrBadParamInput:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name is required"})
+	case domain.ErrBuiltInRole, domain.ErrUnknownPermission:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case domain.ErrConflict:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "A role with this name already exists"})
+	case domain.ErrRoleInUse:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
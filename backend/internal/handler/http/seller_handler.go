@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/internal/service"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// SellerHandler serves /seller, where a user runs their own shop and its catalog
+type SellerHandler struct {
+	shopService    domain.ShopService
+	productService service.ProductService
+	auditLogger    domain.AuditLogger
+}
+
+func NewSellerHandler(shopService domain.ShopService, productService service.ProductService, auditLogger domain.AuditLogger) *SellerHandler {
+	return &SellerHandler{
+		shopService:    shopService,
+		productService: productService,
+		auditLogger:    auditLogger,
+	}
+}
+
+// ApplyShop godoc
+// @Summary Open a shop
+// @Description Apply for a seller shop. It starts pending and can list products once an admin approved it.
+// @Tags seller
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateShopRequest true "Create Shop Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/shop [post]
+func (h *SellerHandler) ApplyShop(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	var req domain.CreateShopRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	shop, err := h.shopService.Apply(c.Context(), user.UserID, req)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Shop submitted for review", "data": shop})
+}
+
+// GetShop godoc
+// @Summary Get my shop
+// @Description Get the current user's shop with its review status
+// @Tags seller
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/shop [get]
+func (h *SellerHandler) GetShop(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	shop, err := h.shopService.GetMyShop(c.Context(), user.UserID)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": shop})
+}
+
+// UpdateShop godoc
+// @Summary Update my shop
+// @Description Change the shop's name and description. A rejected shop goes back to the review queue.
+// @Tags seller
+// @Accept json
+// @Produce json
+// @Param request body domain.UpdateShopRequest true "Update Shop Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/shop [put]
+func (h *SellerHandler) UpdateShop(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	var req domain.UpdateShopRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	shop, err := h.shopService.UpdateMyShop(c.Context(), user.UserID, req)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "Shop updated successfully", "data": shop})
+}
+
+// FindProducts godoc
+// @Summary List my products
+// @Description Get a paginated list of the products in the current user's shop
+// @Tags seller
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param search query string false "Search term"
+// @Param category_id query string false "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/products [get]
+func (h *SellerHandler) FindProducts(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	params := domain.ProductQueryParams{
+		Page:       page,
+		Limit:      limit,
+		Search:     c.Query("search"),
+		CategoryID: c.Query("category_id"),
+	}
+
+	products, total, err := h.productService.FindAllForSeller(c.Context(), user.UserID, params)
+	if err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": products,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// CreateProduct godoc
+// @Summary Create product in my shop
+// @Description Add a product to the current user's approved shop
+// @Tags seller
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateProductRequest true "Create Product Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/products [post]
+func (h *SellerHandler) CreateProduct(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	var req domain.CreateProductRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	product, err := h.productService.CreateForSeller(c.Context(), user.UserID, req)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Category not found"})
+		}
+		return sellerProductErrorResponse(c, err)
+	}
+	h.recordProductChange(c, domain.AuditActionProductCreated, product.ID, nil, product)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Product created successfully", "data": product})
+}
+
+// UpdateProduct godoc
+// @Summary Update product in my shop
+// @Description Update a product of the current user's shop
+// @Tags seller
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body domain.CreateProductRequest true "Update Product Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/products/{id} [put]
+func (h *SellerHandler) UpdateProduct(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	var req domain.CreateProductRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.productService.FindByIDForSeller(c.Context(), user.UserID, id)
+	if err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+
+	if err := h.productService.UpdateForSeller(c.Context(), user.UserID, id, req); err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+
+	// Reloaded so the category in the diff matches category_id
+	after, err := h.productService.FindByIDForSeller(c.Context(), user.UserID, id)
+	if err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+	h.recordProductChange(c, domain.AuditActionProductUpdated, id, before, after)
+
+	return c.JSON(fiber.Map{"message": "Product updated successfully", "data": after})
+}
+
+// DeleteProduct godoc
+// @Summary Delete product from my shop
+// @Description Delete a product of the current user's shop
+// @Tags seller
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /seller/products/{id} [delete]
+func (h *SellerHandler) DeleteProduct(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	before, err := h.productService.FindByIDForSeller(c.Context(), user.UserID, id)
+	if err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+
+	if err := h.productService.DeleteForSeller(c.Context(), user.UserID, id); err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+	h.recordProductChange(c, domain.AuditActionProductDeleted, id, before, nil)
+
+	return c.JSON(fiber.Map{"message": "Product deleted successfully"})
+}
+
+// RequireOwnProduct lets the request through to VariantHandler or ProductImageHandler if product :id is in the user's shop
+func (h *SellerHandler) RequireOwnProduct(c *fiber.Ctx) error {
+	user := c.Locals("user").(*utils.JWTClaims)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	if _, err := h.productService.FindByIDForSeller(c.Context(), user.UserID, id); err != nil {
+		return sellerProductErrorResponse(c, err)
+	}
+	return c.Next()
+}
+
+// recordProductChange audits a seller's change to their catalog, like ProductHandler does for admins
+func (h *SellerHandler) recordProductChange(c *fiber.Ctx, action string, productID uuid.UUID, before, after *domain.Product) {
+	entry := auditEntry(c, action, domain.AuditTargetProduct, productID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func sellerProductErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product not found"})
+	case domain.ErrNoShop, domain.ErrShopNotApproved:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/internal/service"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type ShopHandler struct {
+	shopService    domain.ShopService
+	productService service.ProductService
+	pricingService domain.PricingService
+	auditLogger    domain.AuditLogger
+}
+
+func NewShopHandler(shopService domain.ShopService, productService service.ProductService, pricingService domain.PricingService, auditLogger domain.AuditLogger) *ShopHandler {
+	return &ShopHandler{
+		shopService:    shopService,
+		productService: productService,
+		pricingService: pricingService,
+		auditLogger:    auditLogger,
+	}
+}
+
+// FindBySlug godoc
+// @Summary Get shop
+// @Description Get the public page of an approved seller shop
+// @Tags shops
+// @Produce json
+// @Param slug path string true "Shop slug"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /shops/{slug} [get]
+func (h *ShopHandler) FindBySlug(c *fiber.Ctx) error {
+	shop, err := h.shopService.GetPublicShop(c.Context(), c.Params("slug"))
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": shop})
+}
+
+// GetProducts godoc
+// @Summary Get shop products
+// @Description Get a paginated list of an approved shop's products
+// @Tags shops
+// @Produce json
+// @Param slug path string true "Shop slug"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param search query string false "Search term"
+// @Param category_id query string false "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /shops/{slug}/products [get]
+func (h *ShopHandler) GetProducts(c *fiber.Ctx) error {
+	shop, err := h.shopService.GetPublicShop(c.Context(), c.Params("slug"))
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	params := domain.ProductQueryParams{
+		Page:       page,
+		Limit:      limit,
+		Search:     c.Query("search"),
+		CategoryID: c.Query("category_id"),
+		ShopID:     shop.ID.String(),
+	}
+
+	products, total, err := h.productService.FindAll(c.Context(), params)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := applyCustomerPrices(c, h.pricingService, products); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": products,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// FindAll godoc
+// @Summary List shops
+// @Description Get a paginated list of seller shops, e.g. status=pending for the review queue (Admin only)
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Param status query string false "pending, approved or rejected"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/shops [get]
+func (h *ShopHandler) FindAll(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	params := domain.ShopQueryParams{
+		Page:   page,
+		Limit:  limit,
+		Status: c.Query("status"),
+	}
+
+	shops, total, err := h.shopService.ListShops(c.Context(), params)
+	if err != nil {
+		if err == domain.ErrBadParamInput {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid status"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": shops,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+// Approve godoc
+// @Summary Approve shop
+// @Description Approve a pending shop, or reinstate a suspended one, so its owner can sell (Admin only)
+// @Tags admin
+// @Produce json
+// @Param id path string true "Shop ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/shops/{id}/approve [post]
+func (h *ShopHandler) Approve(c *fiber.Ctx) error {
+	reviewer := c.Locals("user").(*utils.JWTClaims)
+
+	shopID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Shop ID"})
+	}
+
+	before, err := h.shopService.GetShop(c.Context(), shopID)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+	shop, err := h.shopService.ApproveShop(c.Context(), reviewer.UserID, shopID)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+	h.recordShopChange(c, domain.AuditActionShopApproved, before, shop)
+
+	return c.JSON(fiber.Map{"message": "Shop approved", "data": shop})
+}
+
+// Reject godoc
+// @Summary Reject shop
+// @Description Reject a pending shop with a reason shown to its owner, who can edit the shop to ask for another review (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Shop ID"
+// @Param request body domain.RejectShopRequest true "Reject Shop Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/shops/{id}/reject [post]
+func (h *ShopHandler) Reject(c *fiber.Ctx) error {
+	reviewer := c.Locals("user").(*utils.JWTClaims)
+
+	shopID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Shop ID"})
+	}
+
+	var req domain.RejectShopRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.shopService.GetShop(c.Context(), shopID)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+	shop, err := h.shopService.RejectShop(c.Context(), reviewer.UserID, shopID, req)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+	h.recordShopChange(c, domain.AuditActionShopRejected, before, shop)
+
+	return c.JSON(fiber.Map{"message": "Shop rejected", "data": shop})
+}
+
+// Suspend godoc
+// @Summary Suspend shop
+// @Description Suspend an approved shop with a reason shown to its owner. Its products leave the catalog until an admin approves the shop again (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Shop ID"
+// @Param request body domain.RejectShopRequest true "Suspend Shop Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/shops/{id}/suspend [post]
+func (h *ShopHandler) Suspend(c *fiber.Ctx) error {
+	reviewer := c.Locals("user").(*utils.JWTClaims)
+
+	shopID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Shop ID"})
+	}
+
+	var req domain.RejectShopRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.shopService.GetShop(c.Context(), shopID)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+	shop, err := h.shopService.SuspendShop(c.Context(), reviewer.UserID, shopID, req)
+	if err != nil {
+		return shopErrorResponse(c, err)
+	}
+	h.recordShopChange(c, domain.AuditActionShopSuspended, before, shop)
+
+	return c.JSON(fiber.Map{"message": "Shop suspended", "data": shop})
+}
+
+// recordShopChange audits an admin review of a shop
+func (h *ShopHandler) recordShopChange(c *fiber.Ctx, action string, before, after *domain.Shop) {
+	entry := auditEntry(c, action, domain.AuditTargetShop, after.ID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func shopErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shop not found"})
+	case domain.ErrBadParamInput:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name and reason can't be blank"})
+	case domain.ErrConflict:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "You already have a shop, or a shop with this name exists"})
+	case domain.ErrShopNotPending, domain.ErrShopNotSuspendable:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
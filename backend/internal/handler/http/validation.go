@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+// validate checks the `validate` struct tags of request DTOs, see bindBody
+var validate = newValidator()
+
+// FieldError is one entry of the 422 response returned for an invalid request body
+type FieldError struct {
+	Field   string `json:"field"` // JSON name, nested fields are dotted, e.g. items[0].quantity
+	Message string `json:"message"`
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON name, that's what the client sent
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	if err := v.RegisterValidation("password", validatePassword); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// validatePassword requires at least 8 characters with a letter and a digit
+func validatePassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	if len([]rune(password)) < 8 {
+		return false
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	return hasLetter && hasDigit
+}
+
+// bindBody parses the JSON body into req and runs its validate tags.
+// Pass a non-nil error to bindErrorResponse to reply.
+func bindBody(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return domain.ErrBadParamInput
+	}
+	return validate.Struct(req)
+}
+
+// bindErrorResponse replies 400 to a malformed body and 422 with the field errors to an invalid one
+func bindErrorResponse(c *fiber.Ctx, err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{Field: fieldPath(fe), Message: fieldMessage(fe)})
+	}
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"error":  "Validation failed",
+		"fields": fields,
+	})
+}
+
+// passwordPolicyResponse reports a password rejected by domain.PasswordPolicy like a failed validation of field
+func passwordPolicyResponse(c *fiber.Ctx, field string, policyErr *domain.PasswordPolicyError) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"error":  "Validation failed",
+		"fields": []FieldError{{Field: field, Message: policyErr.Reason}},
+	})
+}
+
+// fieldPath drops the struct name from the namespace, "RegisterRequest.email" becomes "email"
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "password":
+		return "must be at least 8 characters and contain a letter and a digit"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min", "max", "len":
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fe.Tag()]
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters", bound, fe.Param())
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return fmt.Sprintf("must contain %s %s items", bound, fe.Param())
+		default:
+			return fmt.Sprintf("must be %s %s", bound, fe.Param())
+		}
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be greater than or equal to " + fe.Param()
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/internal/service"
+)
+
+// VariantHandler serves a product's options and variants, for admins under /products
+// and for sellers under /seller/products after SellerHandler.RequireOwnProduct
+type VariantHandler struct {
+	service     service.VariantService
+	auditLogger domain.AuditLogger
+}
+
+func NewVariantHandler(service service.VariantService, auditLogger domain.AuditLogger) *VariantHandler {
+	return &VariantHandler{service: service, auditLogger: auditLogger}
+}
+
+// FindAll godoc
+// @Summary List product variants
+// @Description Get the variants of a product with their option values, SKU, price and stock
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/variants [get]
+func (h *VariantHandler) FindAll(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	variants, err := h.service.FindVariants(c.Context(), productID)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": variants})
+}
+
+// AddOption godoc
+// @Summary Add product option
+// @Description Add an option type such as Size or Color to a product. Options can only change while the product has no variants.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body domain.CreateProductOptionRequest true "Create Option Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/options [post]
+func (h *VariantHandler) AddOption(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	var req domain.CreateProductOptionRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	option, err := h.service.AddOption(c.Context(), productID, req)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+	h.recordVariantChange(c, domain.AuditActionProductOptionCreated, productID, nil, option)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Option added", "data": option})
+}
+
+// DeleteOption godoc
+// @Summary Delete product option
+// @Description Remove an option type from a product that has no variants
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param optionId path string true "Option ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/options/{optionId} [delete]
+func (h *VariantHandler) DeleteOption(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+	optionID, err := uuid.Parse(c.Params("optionId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Option ID"})
+	}
+
+	option, err := h.service.DeleteOption(c.Context(), productID, optionID)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+	h.recordVariantChange(c, domain.AuditActionProductOptionDeleted, productID, option, nil)
+
+	return c.JSON(fiber.Map{"message": "Option deleted"})
+}
+
+// CreateVariant godoc
+// @Summary Create product variant
+// @Description Add a variant with one value per product option, e.g. {"Size": "M", "Color": "Red"}. Without a price it sells at the product's price.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body domain.CreateVariantRequest true "Create Variant Request"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/variants [post]
+func (h *VariantHandler) CreateVariant(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+
+	var req domain.CreateVariantRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	variant, err := h.service.CreateVariant(c.Context(), productID, req)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+	h.recordVariantChange(c, domain.AuditActionProductVariantCreated, productID, nil, variant)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "Variant created successfully", "data": variant})
+}
+
+// UpdateVariant godoc
+// @Summary Update product variant
+// @Description Change a variant's SKU, price, stock and image. A null price sells it at the product's price.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param request body domain.UpdateVariantRequest true "Update Variant Request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/variants/{variantId} [put]
+func (h *VariantHandler) UpdateVariant(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+	variantID, err := uuid.Parse(c.Params("variantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Variant ID"})
+	}
+
+	var req domain.UpdateVariantRequest
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
+	}
+
+	before, err := h.service.FindVariant(c.Context(), productID, variantID)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+
+	variant, err := h.service.UpdateVariant(c.Context(), productID, variantID, req)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+	h.recordVariantChange(c, domain.AuditActionProductVariantUpdated, productID, before, variant)
+
+	return c.JSON(fiber.Map{"message": "Variant updated successfully", "data": variant})
+}
+
+// DeleteVariant godoc
+// @Summary Delete product variant
+// @Description Delete a variant, it is also removed from carts. Orders keep its SKU and title.
+// @Tags products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/variants/{variantId} [delete]
+func (h *VariantHandler) DeleteVariant(c *fiber.Ctx) error {
+	productID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid UUID"})
+	}
+	variantID, err := uuid.Parse(c.Params("variantId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid Variant ID"})
+	}
+
+	before, err := h.service.FindVariant(c.Context(), productID, variantID)
+	if err != nil {
+		return variantErrorResponse(c, err)
+	}
+
+	if err := h.service.DeleteVariant(c.Context(), productID, variantID); err != nil {
+		return variantErrorResponse(c, err)
+	}
+	h.recordVariantChange(c, domain.AuditActionProductVariantDeleted, productID, before, nil)
+
+	return c.JSON(fiber.Map{"message": "Variant deleted successfully"})
+}
+
+// recordVariantChange audits a change to a product's options or variants, the event targets the product
+func (h *VariantHandler) recordVariantChange(c *fiber.Ctx, action string, productID uuid.UUID, before, after interface{}) {
+	entry := auditEntry(c, action, domain.AuditTargetProduct, productID.String())
+	entry.Before = before
+	entry.After = after
+	h.auditLogger.Record(c.Context(), entry)
+}
+
+func variantErrorResponse(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrNotFound:
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Product, option or variant not found"})
+	case domain.ErrBadParamInput:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Name and SKU can't be blank"})
+	case domain.ErrInvalidVariant:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case domain.ErrConflict:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "The option, SKU or combination of option values already exists"})
+	case domain.ErrProductHasVariants:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
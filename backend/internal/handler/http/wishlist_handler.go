@@ -23,14 +23,15 @@ func NewWishlistHandler(service domain.WishlistService) *WishlistHandler {
 // @Param request body domain.ToggleWishlistRequest true "Toggle Wishlist Request"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /wishlist/toggle [post]
 func (h *WishlistHandler) Toggle(c *fiber.Ctx) error {
 	user := c.Locals("user").(*utils.JWTClaims)
 
 	var req domain.ToggleWishlistRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": domain.ErrBadParamInput.Error()})
+	if err := bindBody(c, &req); err != nil {
+		return bindErrorResponse(c, err)
 	}
 
 	action, err := h.service.ToggleWishlist(c.Context(), user.UserID, req.ProductID)
@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+// localStorage writes files below STORAGE_LOCAL_DIR, main serves that directory on LocalStorageRoute
+type localStorage struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocalStorage(cfg *config.Config) domain.Storage {
+	publicURL := cfg.Storage.PublicURL
+	if publicURL == "" {
+		publicURL = "http://localhost:" + cfg.Server.Port + LocalStorageRoute
+	}
+	return &localStorage{
+		dir:       cfg.Storage.LocalDir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	filename, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create storage dir: %w", err)
+	}
+	// Write to a temporary file first so a failed upload never leaves half a file behind
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	return s.publicURL + "/" + key, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	filename, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// path maps a key to a file inside the storage dir, keys can't climb out of it
+func (s *localStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleaned)), nil
+}
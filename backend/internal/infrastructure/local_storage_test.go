@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/user/go-ecommerce/internal/config"
+)
+
+func newTestLocalStorage(t *testing.T) *localStorage {
+	t.Helper()
+	cfg := &config.Config{
+		Server:  config.ServerConfig{Port: "8080"},
+		Storage: config.StorageConfig{LocalDir: t.TempDir()},
+	}
+	return NewLocalStorage(cfg).(*localStorage)
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	s := newTestLocalStorage(t)
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "products/1/photo.jpg", want: "products/1/photo.jpg"},
+		{key: "photo.jpg", want: "photo.jpg"},
+		{key: "", wantErr: true},
+		{key: ".", wantErr: true},
+		{key: "/", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+		{key: "../photo.jpg", wantErr: true},
+		{key: "products/../../photo.jpg", wantErr: true},
+		{key: "products/../photo.jpg", wantErr: true},
+		{key: "products/./photo.jpg", wantErr: true},
+		{key: "products//photo.jpg", wantErr: true},
+		{key: "products/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := s.path(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("path(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if err == nil && got != filepath.Join(s.dir, filepath.FromSlash(tt.want)) {
+				t.Errorf("path(%q) = %q, want %q inside %q", tt.key, got, tt.want, s.dir)
+			}
+		})
+	}
+}
+
+func TestLocalStoragePutDelete(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+	key := "products/1/photo.jpg"
+
+	url, err := s.Put(ctx, key, []byte("jpeg"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if want := "http://localhost:8080" + LocalStorageRoute + "/" + key; url != want {
+		t.Errorf("Put URL = %q, want %q", url, want)
+	}
+	filename := filepath.Join(s.dir, "products", "1", "photo.jpg")
+	if data, err := os.ReadFile(filename); err != nil || string(data) != "jpeg" {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+
+	if _, err := s.Put(ctx, "../escape.jpg", []byte("jpeg"), "image/jpeg"); err == nil {
+		t.Error("Put accepted a key outside the storage dir")
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+	// Deleting a missing file isn't an error, the row may outlive it
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing file: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]+`)
+
+// logMailer doesn't deliver anything. It logs each message and, when MAIL_OUTPUT_DIR
+// is set, writes it as an .eml file so flows like password reset can be tested offline.
+type logMailer struct {
+	from      string
+	outputDir string
+}
+
+func NewLogMailer(cfg *config.Config) domain.Mailer {
+	return &logMailer{
+		from:      cfg.Mail.From,
+		outputDir: cfg.Mail.OutputDir,
+	}
+}
+
+func (m *logMailer) Send(ctx context.Context, msg domain.MailMessage) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+
+	if m.outputDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail output dir: %w", err)
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	if err := os.WriteFile(filepath.Join(m.outputDir, name), buildMessage(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
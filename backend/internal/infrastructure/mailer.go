@@ -0,0 +1,21 @@
+package infrastructure
+
+import (
+	"log"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+// NewMailer picks the Mailer implementation from MAIL_DRIVER
+func NewMailer(cfg *config.Config) domain.Mailer {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "log", "":
+		return NewLogMailer(cfg)
+	default:
+		log.Printf("Unknown MAIL_DRIVER %q, falling back to log mailer", cfg.Mail.Driver)
+		return NewLogMailer(cfg)
+	}
+}
@@ -0,0 +1,67 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+	host string
+}
+
+func NewSMTPMailer(cfg *config.Config) domain.Mailer {
+	var auth smtp.Auth
+	if cfg.Mail.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.SMTPHost)
+	}
+	return &smtpMailer{
+		addr: net.JoinHostPort(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort),
+		from: cfg.Mail.From,
+		auth: auth,
+		host: cfg.Mail.SMTPHost,
+	}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg domain.MailMessage) error {
+	// net/smtp has no context support, run it in the background and honour cancellation
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, buildMessage(m.from, msg))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// headerSanitizer prevents header injection through user supplied values
+var headerSanitizer = strings.NewReplacer("\r", "", "\n", "")
+
+func buildMessage(from string, msg domain.MailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerSanitizer.Replace(from) + "\r\n")
+	b.WriteString("To: " + headerSanitizer.Replace(msg.To) + "\r\n")
+	b.WriteString("Subject: " + headerSanitizer.Replace(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
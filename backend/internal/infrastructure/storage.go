@@ -0,0 +1,24 @@
+package infrastructure
+
+import (
+	"log"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+// LocalStorageRoute is where the API serves the files of the local storage driver
+const LocalStorageRoute = "/uploads"
+
+// NewStorage picks the Storage implementation from STORAGE_DRIVER
+func NewStorage(cfg *config.Config) (domain.Storage, error) {
+	switch cfg.Storage.Driver {
+	case "s3":
+		return NewS3Storage(cfg)
+	case "local", "":
+		return NewLocalStorage(cfg), nil
+	default:
+		log.Printf("Unknown STORAGE_DRIVER %q, falling back to local storage", cfg.Storage.Driver)
+		return NewLocalStorage(cfg), nil
+	}
+}
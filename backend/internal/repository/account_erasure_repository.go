@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Names of deleted accounts and their shops, orders still show who they belonged to as these
+const (
+	anonymizedName     = "Deleted user"
+	anonymizedShopName = "Deleted shop"
+)
+
+type accountErasureRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountErasureRepository(db *gorm.DB) domain.AccountErasureRepository {
+	return &accountErasureRepository{db: db}
+}
+
+func (r *accountErasureRepository) Anonymize(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"name":               anonymizedName,
+			"email":              fmt.Sprintf("deleted-%s@deleted.invalid", userID), // Keeps the unique constraint and frees the address
+			"password":           "",
+			"verified_at":        nil,
+			"totp_secret":        "",
+			"two_factor_enabled": false,
+			"disabled_at":        at,
+			"anonymized_at":      at,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		if err := tx.Model(&domain.Address{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"recipient_name": anonymizedName,
+			"phone_number":   "",
+			"street":         "",
+			"city":           "",
+			"state":          "",
+			"zip_code":       "",
+			"is_primary":     false,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("cart_id IN (?)", tx.Model(&domain.Cart{}).Select("id").Where("user_id = ?", userID)).
+			Delete(&domain.CartItem{}).Error; err != nil {
+			return err
+		}
+
+		// Rows that only exist for the user, nothing else references them.
+		// Sessions and refresh tokens hold the IP addresses and user agents of their devices.
+		for _, model := range []interface{}{
+			&domain.RefreshToken{},
+			&domain.Session{},
+			&domain.Cart{},
+			&domain.Wishlist{},
+			&domain.UserIdentity{},
+			&domain.RecoveryCode{},
+			&domain.UserToken{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// The shop and its products stay for the orders placed with it, but it is closed and loses its name
+		if err := tx.Model(&domain.Shop{}).Where("owner_id = ?", userID).Updates(map[string]interface{}{
+			"name":             anonymizedShopName,
+			"slug":             fmt.Sprintf("deleted-%s", userID),
+			"description":      "",
+			"status":           domain.ShopStatusRejected,
+			"rejection_reason": "The owner deleted their account",
+			"reviewed_at":      at,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.APIKey{}).
+			Where("created_by_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", at).Error
+	})
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/domain"
@@ -24,6 +25,7 @@ func (r *cartRepository) FindBytesUserID(ctx context.Context, userID uuid.UUID)
 			return db.Order("created_at desc") // Show newest items first
 		}).
 		Preload("Items.Product").
+		Preload("Items.Variant").
 		Where("user_id = ?", userID).
 		First(&cart).Error
 	
@@ -36,6 +38,24 @@ func (r *cartRepository) FindBytesUserID(ctx context.Context, userID uuid.UUID)
 	return &cart, nil
 }
 
+func (r *cartRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Cart, error) {
+	var cart domain.Cart
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at desc")
+		}).
+		Preload("Items.Product").
+		Preload("Items.Variant").
+		First(&cart, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // Same as FindBytesUserID, an expired or merged guest cart is simply gone
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+
 func (r *cartRepository) Create(ctx context.Context, cart *domain.Cart) error {
 	return r.db.WithContext(ctx).Create(cart).Error
 }
@@ -43,8 +63,7 @@ func (r *cartRepository) Create(ctx context.Context, cart *domain.Cart) error {
 func (r *cartRepository) AddItem(ctx context.Context, item *domain.CartItem) error {
     // Check if item exists in cart
     var existingItem domain.CartItem
-    err := r.db.WithContext(ctx).
-        Where("cart_id = ? AND product_id = ?", item.CartID, item.ProductID).
+    err := whereCartLine(r.db.WithContext(ctx), item.CartID, item.Line()).
         First(&existingItem).Error
     
     if err == nil {
@@ -68,3 +87,56 @@ func (r *cartRepository) RemoveItem(ctx context.Context, itemID uuid.UUID) error
 func (r *cartRepository) ClearCart(ctx context.Context, cartID uuid.UUID) error {
 	return r.db.WithContext(ctx).Where("cart_id = ?", cartID).Delete(&domain.CartItem{}).Error
 }
+
+func (r *cartRepository) DeleteGuestCarts(ctx context.Context, createdBefore time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&domain.Cart{}).Select("id").Where("user_id IS NULL AND created_at < ?", createdBefore)
+		if err := tx.Where("cart_id IN (?)", stale).Delete(&domain.CartItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id IS NULL AND created_at < ?", createdBefore).Delete(&domain.Cart{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+func (r *cartRepository) MergeCart(ctx context.Context, guestCartID, targetCartID uuid.UUID, quantities map[domain.CartLine]int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for line, quantity := range quantities {
+			var item domain.CartItem
+			err := whereCartLine(tx, targetCartID, line).First(&item).Error
+			if err == gorm.ErrRecordNotFound {
+				item = domain.CartItem{CartID: targetCartID, ProductID: line.ProductID, Quantity: quantity}
+				if line.VariantID != uuid.Nil {
+					item.VariantID = &line.VariantID
+				}
+				if err := tx.Create(&item).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&item).Update("quantity", quantity).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("cart_id = ?", guestCartID).Delete(&domain.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Cart{}, "id = ?", guestCartID).Error
+	})
+}
+
+// whereCartLine finds the cart's item for the product, and the variant if it has one
+func whereCartLine(db *gorm.DB, cartID uuid.UUID, line domain.CartLine) *gorm.DB {
+	db = db.Where("cart_id = ? AND product_id = ?", cartID, line.ProductID)
+	if line.VariantID == uuid.Nil {
+		return db.Where("variant_id IS NULL")
+	}
+	return db.Where("variant_id = ?", line.VariantID)
+}
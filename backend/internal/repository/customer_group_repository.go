@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type customerGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerGroupRepository(db *gorm.DB) domain.CustomerGroupRepository {
+	return &customerGroupRepository{db: db}
+}
+
+func (r *customerGroupRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.CustomerGroup, error) {
+	var group domain.CustomerGroup
+	if err := r.db.WithContext(ctx).Preload("PriceRules").First(&group, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *customerGroupRepository) FindByName(ctx context.Context, name string) (*domain.CustomerGroup, error) {
+	var group domain.CustomerGroup
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *customerGroupRepository) FindAll(ctx context.Context) ([]domain.CustomerGroup, error) {
+	var groups []domain.CustomerGroup
+	err := r.db.WithContext(ctx).Order("name").Find(&groups).Error
+	return groups, err
+}
+
+func (r *customerGroupRepository) Create(ctx context.Context, group *domain.CustomerGroup) error {
+	return r.db.WithContext(ctx).Create(group).Error
+}
+
+func (r *customerGroupRepository) Update(ctx context.Context, group *domain.CustomerGroup) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(group).Error
+}
+
+func (r *customerGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&domain.GroupPriceRule{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.CustomerGroup{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *customerGroupRepository) FindPriceRules(ctx context.Context, groupID uuid.UUID) ([]domain.GroupPriceRule, error) {
+	var rules []domain.GroupPriceRule
+	err := r.db.WithContext(ctx).Where("group_id = ?", groupID).Find(&rules).Error
+	return rules, err
+}
+
+func (r *customerGroupRepository) SavePriceRule(ctx context.Context, rule *domain.GroupPriceRule) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("group_id = ?", rule.GroupID)
+		if rule.ProductID != nil {
+			query = query.Where("product_id = ?", *rule.ProductID)
+		} else {
+			query = query.Where("category_id = ?", *rule.CategoryID)
+		}
+
+		var existing domain.GroupPriceRule
+		err := query.First(&existing).Error
+		switch err {
+		case nil:
+			rule.ID = existing.ID
+			rule.CreatedAt = existing.CreatedAt
+			return tx.Save(rule).Error
+		case gorm.ErrRecordNotFound:
+			return tx.Create(rule).Error
+		}
+		return err
+	})
+}
+
+func (r *customerGroupRepository) DeletePriceRule(ctx context.Context, groupID, ruleID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND group_id = ?", ruleID, groupID).Delete(&domain.GroupPriceRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
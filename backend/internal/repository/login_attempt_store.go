@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type memoryEntry struct {
+	attempt   domain.LoginAttempt
+	expiresAt time.Time
+}
+
+// memoryLoginAttemptStore keeps counters in process memory. Counters are lost on
+// restart and not shared between instances.
+type memoryLoginAttemptStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryLoginAttemptStore() domain.LoginAttemptStore {
+	store := &memoryLoginAttemptStore{entries: make(map[string]memoryEntry)}
+	go store.cleanup()
+	return store
+}
+
+func (s *memoryLoginAttemptStore) Get(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+	attempt := entry.attempt
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) Increment(ctx context.Context, key string, window time.Duration) (domain.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expiresAt) || (now.Sub(entry.attempt.WindowStart) > window && now.After(entry.attempt.LockedUntil)) {
+		entry = memoryEntry{attempt: domain.LoginAttempt{WindowStart: now}}
+	}
+
+	entry.attempt.Failures++
+	if expiresAt := now.Add(window); expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+	s.entries[key] = entry
+	return entry.attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) Decrement(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.attempt.Failures == 0 {
+		return nil
+	}
+	entry.attempt.Failures--
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		entry = memoryEntry{attempt: domain.LoginAttempt{WindowStart: now}}
+	}
+
+	if until.After(entry.attempt.LockedUntil) {
+		entry.attempt.LockedUntil = until
+	}
+	if expiresAt := now.Add(ttl); expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// cleanup drops expired entries so the map doesn't grow with every IP ever seen
+func (s *memoryLoginAttemptStore) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := time.Now()
+		s.mu.Lock()
+		for key, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
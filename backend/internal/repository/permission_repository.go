@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+)
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) domain.PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (r *permissionRepository) FindAll(ctx context.Context) ([]domain.Permission, error) {
+	var permissions []domain.Permission
+	err := r.db.WithContext(ctx).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *permissionRepository) FindByNames(ctx context.Context, names []string) ([]domain.Permission, error) {
+	var permissions []domain.Permission
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *permissionRepository) SyncCatalog(ctx context.Context, catalog []domain.Permission) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		names := make([]string, 0, len(catalog))
+		synced := make([]domain.Permission, 0, len(catalog))
+		for _, p := range catalog {
+			var permission domain.Permission
+			err := tx.Where("name = ?", p.Name).First(&permission).Error
+			switch {
+			case err == gorm.ErrRecordNotFound:
+				permission = domain.Permission{ID: uuid.New(), Name: p.Name, Description: p.Description}
+				if err := tx.Create(&permission).Error; err != nil {
+					return err
+				}
+			case err != nil:
+				return err
+			case permission.Description != p.Description:
+				if err := tx.Model(&permission).Update("description", p.Description).Error; err != nil {
+					return err
+				}
+			}
+			names = append(names, p.Name)
+			synced = append(synced, permission)
+		}
+
+		// Permissions dropped from the catalog aren't checked anywhere anymore
+		var staleIDs []uuid.UUID
+		if err := tx.Model(&domain.Permission{}).Where("name NOT IN ?", names).Pluck("id", &staleIDs).Error; err != nil {
+			return err
+		}
+		if len(staleIDs) > 0 {
+			if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id IN ?", staleIDs).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&domain.Permission{}, "id IN ?", staleIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		var admin domain.Role
+		if err := tx.Where("name = ?", domain.RoleAdmin).First(&admin).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil // Not seeded yet
+			}
+			return err
+		}
+		return tx.Model(&admin).Association("Permissions").Replace(synced)
+	})
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+)
+
+type productImageRepository struct {
+	db *gorm.DB
+}
+
+func NewProductImageRepository(db *gorm.DB) domain.ProductImageRepository {
+	return &productImageRepository{db: db}
+}
+
+func (r *productImageRepository) FindByProductID(ctx context.Context, productID uuid.UUID) ([]domain.ProductImage, error) {
+	var images []domain.ProductImage
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("position, created_at").Find(&images).Error
+	return images, err
+}
+
+func (r *productImageRepository) FindByID(ctx context.Context, productID, id uuid.UUID) (*domain.ProductImage, error) {
+	var image domain.ProductImage
+	if err := r.db.WithContext(ctx).Where("id = ? AND product_id = ?", id, productID).First(&image).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *productImageRepository) Create(ctx context.Context, image *domain.ProductImage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// New images go to the end of the gallery
+		var last struct{ Position *int }
+		if err := tx.Model(&domain.ProductImage{}).Select("MAX(position) AS position").Where("product_id = ?", image.ProductID).Scan(&last).Error; err != nil {
+			return err
+		}
+		image.Position = 0
+		if last.Position != nil {
+			image.Position = *last.Position + 1
+		}
+
+		if err := tx.Create(image).Error; err != nil {
+			return err
+		}
+		return syncProductCover(tx, image.ProductID)
+	})
+}
+
+func (r *productImageRepository) Update(ctx context.Context, image *domain.ProductImage) error {
+	return r.db.WithContext(ctx).Save(image).Error
+}
+
+func (r *productImageRepository) Delete(ctx context.Context, productID, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND product_id = ?", id, productID).Delete(&domain.ProductImage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		return syncProductCover(tx, productID)
+	})
+}
+
+func (r *productImageRepository) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			result := tx.Model(&domain.ProductImage{}).Where("id = ? AND product_id = ?", id, productID).Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrNotFound
+			}
+		}
+		return syncProductCover(tx, productID)
+	})
+}
+
+// syncProductCover sets a product's image_url to its first gallery image, or clears it once the gallery is empty
+func syncProductCover(tx *gorm.DB, productID uuid.UUID) error {
+	return tx.Exec(
+		"UPDATE products SET image_url = COALESCE((SELECT url FROM product_images WHERE product_id = ? ORDER BY position, created_at LIMIT 1), '') WHERE id = ?",
+		productID, productID,
+	).Error
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type productRepository struct {
@@ -24,7 +25,7 @@ func (r *productRepository) FindAll(ctx context.Context, params domain.ProductQu
 	var products []domain.Product
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&domain.Product{}).Preload("Category")
+	query := r.listed(r.db.WithContext(ctx).Model(&domain.Product{})).Preload("Category").Preload("Shop")
 
 	if params.Search != "" {
 		query = query.Where("name ILIKE ?", "%"+params.Search+"%")
@@ -32,6 +33,9 @@ func (r *productRepository) FindAll(ctx context.Context, params domain.ProductQu
 	if params.CategoryID != "" {
 		query = query.Where("category_id = ?", params.CategoryID)
 	}
+	if params.ShopID != "" {
+		query = query.Where("shop_id = ?", params.ShopID)
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -47,7 +51,18 @@ func (r *productRepository) FindAll(ctx context.Context, params domain.ProductQu
 
 func (r *productRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	var product domain.Product
-	if err := r.db.WithContext(ctx).Preload("Category").First(&product, id).Error; err != nil {
+	if err := r.withDetails(r.db.WithContext(ctx)).Preload("Category").Preload("Shop").First(&product, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productRepository) FindListedByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	var product domain.Product
+	if err := r.withDetails(r.listed(r.db.WithContext(ctx))).Preload("Category").Preload("Shop").First(&product, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrNotFound
 		}
@@ -58,7 +73,7 @@ func (r *productRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain
 
 func (r *productRepository) FindBySlug(ctx context.Context, slug string) (*domain.Product, error) {
 	var product domain.Product
-	if err := r.db.WithContext(ctx).Preload("Category").Where("slug = ?", slug).First(&product).Error; err != nil {
+	if err := r.withDetails(r.listed(r.db.WithContext(ctx))).Preload("Category").Preload("Shop").Where("slug = ?", slug).First(&product).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrNotFound
 		}
@@ -68,7 +83,8 @@ func (r *productRepository) FindBySlug(ctx context.Context, slug string) (*domai
 }
 
 func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
-	return r.db.WithContext(ctx).Save(product).Error
+	// Omit associations, the preloaded category or shop would overwrite a changed foreign key
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(product).Error
 }
 
 func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -81,3 +97,35 @@ func (r *productRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 	return nil
 }
+
+func (r *productRepository) DeleteInShop(ctx context.Context, shopID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("shop_id = ?", shopID).Delete(&domain.Product{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// listed hides the products of shops that aren't approved (pending, rejected or suspended)
+func (r *productRepository) listed(db *gorm.DB) *gorm.DB {
+	approved := r.db.Model(&domain.Shop{}).Select("id").Where("status = ?", domain.ShopStatusApproved)
+	return db.Where("(products.shop_id IS NULL OR products.shop_id IN (?))", approved)
+}
+
+// withDetails preloads the options, variants and gallery shown on a product's page
+func (r *productRepository) withDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, created_at")
+		}).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, created_at")
+		}).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		Preload("Variants.Options")
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) domain.RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Role, error) {
+	var role domain.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").First(&role, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*domain.Role, error) {
+	var role domain.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindAll(ctx context.Context) ([]domain.Role, error) {
+	var roles []domain.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *domain.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+func (r *roleRepository) Update(ctx context.Context, role *domain.Role) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(role).Error
+}
+
+func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role := &domain.Role{ID: id}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		result := tx.Delete(role)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *roleRepository) AddPermissions(ctx context.Context, roleID uuid.UUID, permissions []domain.Permission) error {
+	return r.db.WithContext(ctx).Model(&domain.Role{ID: roleID}).Association("Permissions").Append(permissions)
+}
+
+func (r *roleRepository) RemovePermission(ctx context.Context, roleID uuid.UUID, permission domain.Permission) error {
+	return r.db.WithContext(ctx).Model(&domain.Role{ID: roleID}).Association("Permissions").Delete(&permission)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type shopRepository struct {
+	db *gorm.DB
+}
+
+func NewShopRepository(db *gorm.DB) domain.ShopRepository {
+	return &shopRepository{db: db}
+}
+
+func (r *shopRepository) Create(ctx context.Context, shop *domain.Shop) error {
+	return r.db.WithContext(ctx).Create(shop).Error
+}
+
+func (r *shopRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Shop, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+func (r *shopRepository) FindBySlug(ctx context.Context, slug string) (*domain.Shop, error) {
+	return r.findOne(ctx, "slug = ?", slug)
+}
+
+func (r *shopRepository) FindByOwnerID(ctx context.Context, ownerID uuid.UUID) (*domain.Shop, error) {
+	return r.findOne(ctx, "owner_id = ?", ownerID)
+}
+
+func (r *shopRepository) FindAll(ctx context.Context, params domain.ShopQueryParams) ([]domain.Shop, int64, error) {
+	var shops []domain.Shop
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.Shop{})
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Preload("Owner").Order("created_at DESC").Offset(offset).Limit(params.Limit).Find(&shops).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return shops, total, nil
+}
+
+func (r *shopRepository) Update(ctx context.Context, shop *domain.Shop) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(shop).Error
+}
+
+func (r *shopRepository) findOne(ctx context.Context, query string, arg interface{}) (*domain.Shop, error) {
+	var shop domain.Shop
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&shop).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &shop, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/domain"
@@ -33,7 +34,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Role.Permissions").First(&user, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrNotFound
 		}
@@ -41,3 +42,138 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Use
 	}
 	return &user, nil
 }
+
+func (r *userRepository) UpdateName(ctx context.Context, id uuid.UUID, name string) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("password", hashedPassword)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) MarkVerified(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.User{}).
+		Where("id = ? AND verified_at IS NULL", id).
+		Update("verified_at", at).Error
+}
+
+func (r *userRepository) UpdateTwoFactor(ctx context.Context, id uuid.UUID, secret string, enabled bool) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"totp_secret": secret, "two_factor_enabled": enabled}).Error
+}
+
+func (r *userRepository) UpdateTOTPLastStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	// Conditional update so two requests with the same code can't both succeed
+	result := r.db.WithContext(ctx).
+		Model(&domain.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *userRepository) FindAll(ctx context.Context, params domain.UserQueryParams) ([]domain.User, int64, error) {
+	var users []domain.User
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.User{}).Preload("Role")
+
+	if params.Search != "" {
+		query = query.Where("name ILIKE ? OR email ILIKE ?", "%"+params.Search+"%", "%"+params.Search+"%")
+	}
+	if params.RoleID != "" {
+		query = query.Where("role_id = ?", params.RoleID)
+	}
+	switch params.Status {
+	case domain.UserStatusActive:
+		query = query.Where("disabled_at IS NULL")
+	case domain.UserStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (params.Page - 1) * params.Limit
+	if err := query.Order("created_at DESC").Offset(offset).Limit(params.Limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uuid.UUID, roleID *uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("role_id", roleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) AssignRoleWhereMissing(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("role_id IS NULL").Update("role_id", roleID)
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) SetDisabledAt(ctx context.Context, id uuid.UUID, at *time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("disabled_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) CountByRole(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Where("role_id = ?", roleID).Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) CountActiveByRole(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Where("role_id = ? AND disabled_at IS NULL", roleID).Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) UpdateCustomerGroup(ctx context.Context, id uuid.UUID, groupID *uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("customer_group_id", groupID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) CountByCustomerGroup(ctx context.Context, groupID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Where("customer_group_id = ?", groupID).Count(&count).Error
+	return count, err
+}
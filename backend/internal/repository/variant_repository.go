@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type variantRepository struct {
+	db *gorm.DB
+}
+
+func NewVariantRepository(db *gorm.DB) domain.VariantRepository {
+	return &variantRepository{db: db}
+}
+
+func (r *variantRepository) FindOptions(ctx context.Context, productID uuid.UUID) ([]domain.ProductOption, error) {
+	var options []domain.ProductOption
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("position, created_at").Find(&options).Error
+	return options, err
+}
+
+func (r *variantRepository) CreateOption(ctx context.Context, option *domain.ProductOption) error {
+	return r.db.WithContext(ctx).Create(option).Error
+}
+
+func (r *variantRepository) DeleteOption(ctx context.Context, productID, optionID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND product_id = ?", optionID, productID).Delete(&domain.ProductOption{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *variantRepository) FindByProductID(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error) {
+	var variants []domain.ProductVariant
+	err := r.db.WithContext(ctx).Preload("Options").Where("product_id = ?", productID).Order("created_at").Find(&variants).Error
+	return variants, err
+}
+
+func (r *variantRepository) FindByID(ctx context.Context, productID, id uuid.UUID) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	if err := r.db.WithContext(ctx).Preload("Options").Where("id = ? AND product_id = ?", id, productID).First(&variant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *variantRepository) FindBySKU(ctx context.Context, sku string) (*domain.ProductVariant, error) {
+	var variant domain.ProductVariant
+	if err := r.db.WithContext(ctx).Where("sku = ?", sku).First(&variant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+func (r *variantRepository) Create(ctx context.Context, variant *domain.ProductVariant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Creates the option values with it
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		return syncProductStock(tx, variant.ProductID)
+	})
+}
+
+func (r *variantRepository) Update(ctx context.Context, variant *domain.ProductVariant) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(variant).Error; err != nil {
+			return err
+		}
+		return syncProductStock(tx, variant.ProductID)
+	})
+}
+
+func (r *variantRepository) Delete(ctx context.Context, productID, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Option values and cart lines of the variant are removed by their foreign keys
+		result := tx.Where("id = ? AND product_id = ?", id, productID).Delete(&domain.ProductVariant{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		return syncProductStock(tx, productID)
+	})
+}
+
+// syncProductStock sets a product's stock to the sum of its variants', so listings show what's available
+func syncProductStock(tx *gorm.DB, productID uuid.UUID) error {
+	return tx.Exec(
+		"UPDATE products SET stock = (SELECT COALESCE(SUM(stock), 0) FROM product_variants WHERE product_id = ?) WHERE id = ?",
+		productID, productID,
+	).Error
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type adminUserService struct {
+	userRepo        domain.UserRepository
+	roleRepo        domain.RoleRepository
+	sessionService  domain.SessionService
+	rbacService     domain.RBACService
+	revocationStore domain.RevocationStore
+	cfg             *config.Config
+}
+
+func NewAdminUserService(userRepo domain.UserRepository, roleRepo domain.RoleRepository, sessionService domain.SessionService, rbacService domain.RBACService, revocationStore domain.RevocationStore, cfg *config.Config) domain.AdminUserService {
+	return &adminUserService{
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		sessionService:  sessionService,
+		rbacService:     rbacService,
+		revocationStore: revocationStore,
+		cfg:             cfg,
+	}
+}
+
+func (s *adminUserService) ListUsers(ctx context.Context, params domain.UserQueryParams) ([]domain.User, int64, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.Status != "" && params.Status != domain.UserStatusActive && params.Status != domain.UserStatusDisabled {
+		return nil, 0, domain.ErrBadParamInput
+	}
+	if params.RoleID != "" {
+		if _, err := uuid.Parse(params.RoleID); err != nil {
+			return nil, 0, domain.ErrBadParamInput
+		}
+	}
+	return s.userRepo.FindAll(ctx, params)
+}
+
+func (s *adminUserService) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return s.userRepo.GetByID(ctx, id)
+}
+
+func (s *adminUserService) ChangeRole(ctx context.Context, actorID, userID uuid.UUID, req domain.ChangeRoleRequest) (*domain.User, error) {
+	// An admin demoting themselves could leave nobody able to undo it
+	if actorID == userID {
+		return nil, domain.ErrCannotModifySelf
+	}
+
+	if _, err := s.roleRepo.FindByID(ctx, req.RoleID); err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrBadParamInput
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.RoleID != nil && *user.RoleID == req.RoleID {
+		return user, nil
+	}
+	if err := s.ensureNotLastAdmin(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateRole(ctx, userID, &req.RoleID); err != nil {
+		return nil, err
+	}
+	// Access tokens carry the role ID, make the user sign in again to pick up the new one
+	if err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+func (s *adminUserService) DisableUser(ctx context.Context, actorID, userID uuid.UUID) (*domain.User, error) {
+	if actorID == userID {
+		return nil, domain.ErrCannotModifySelf
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return user, nil
+	}
+	if err := s.ensureNotLastAdmin(ctx, user); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SetDisabledAt(ctx, userID, &now); err != nil {
+		return nil, err
+	}
+	// AuthMiddleware rejects the user's tokens right away, impersonation tokens for them included.
+	// Other instances pick it up within the revocation store's recheck interval.
+	s.revocationStore.MarkUserDisabled(userID, true)
+	if err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+	user.DisabledAt = &now
+	return user, nil
+}
+
+func (s *adminUserService) EnableUser(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	if err := s.userRepo.SetDisabledAt(ctx, userID, nil); err != nil {
+		return nil, err
+	}
+	s.revocationStore.MarkUserDisabled(userID, false)
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+func (s *adminUserService) Impersonate(ctx context.Context, impersonator domain.Impersonator, userID uuid.UUID) (*domain.Impersonation, error) {
+	if impersonator.UserID == userID {
+		return nil, domain.ErrCannotImpersonate
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil || user.AnonymizedAt != nil {
+		return nil, domain.ErrAccountDisabled
+	}
+	if user.RoleID == nil || user.Role == nil {
+		// Such a token would be rejected by AuthMiddleware anyway, and without the role
+		// its permissions can't be compared with the support user's
+		return nil, domain.ErrCannotImpersonate
+	}
+
+	// Acting as a user must never grant the support user a permission they don't already have
+	granted, err := s.rbacService.GetPermissions(ctx, impersonator.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range user.Role.Permissions {
+		if _, ok := granted[permission.Name]; !ok {
+			return nil, domain.ErrCannotImpersonate
+		}
+	}
+
+	// Never outlives the support user's own session
+	expiresAt := time.Now().Add(utils.ImpersonationTokenTTL(s.cfg))
+	session, err := s.sessionService.GetSession(ctx, impersonator.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	token, err := utils.GenerateImpersonationToken(user.ID, *user.RoleID, impersonator.UserID, impersonator.SessionID, expiresAt, s.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Impersonation{Token: token, ExpiresAt: expiresAt, User: user}, nil
+}
+
+// ensureNotLastAdmin refuses to take the admin role away from the only active admin,
+// which would leave nobody able to manage users and roles
+func (s *adminUserService) ensureNotLastAdmin(ctx context.Context, user *domain.User) error {
+	if user.DisabledAt != nil || user.Role == nil || user.Role.Name != domain.RoleAdmin {
+		return nil
+	}
+	count, err := s.userRepo.CountActiveByRole(ctx, user.Role.ID)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return domain.ErrLastAdmin
+	}
+	return nil
+}
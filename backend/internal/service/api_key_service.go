@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// apiKeyLastUsedInterval limits last_used_at writes to one per key per interval
+const apiKeyLastUsedInterval = time.Minute
+
+// apiKeyPrefixLength is the number of hex characters identifying a key after APIKeyPrefix
+const apiKeyPrefixLength = 8
+
+type apiKeyService struct {
+	repo        domain.APIKeyRepository
+	userRepo    domain.UserRepository
+	rbacService domain.RBACService
+
+	mu       sync.Mutex
+	lastUsed map[uuid.UUID]time.Time
+}
+
+func NewAPIKeyService(repo domain.APIKeyRepository, userRepo domain.UserRepository, rbacService domain.RBACService) domain.APIKeyService {
+	return &apiKeyService{
+		repo:        repo,
+		userRepo:    userRepo,
+		rbacService: rbacService,
+		lastUsed:    make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, creatorID uuid.UUID, req domain.CreateAPIKeyRequest) (*domain.APIKey, string, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", domain.ErrBadParamInput
+	}
+
+	creator, err := s.userRepo.GetByID(ctx, creatorID)
+	if err != nil {
+		return nil, "", err
+	}
+	scopes, err := s.validateScopes(ctx, creator, req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Key format: gek_<8 hex prefix>_<secret>, only the prefix is stored in plain text
+	b := make([]byte, apiKeyPrefixLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", domain.ErrInternalServerError
+	}
+	prefix := domain.APIKeyPrefix + hex.EncodeToString(b)
+	secret, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", domain.ErrInternalServerError
+	}
+
+	key := &domain.APIKey{
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     utils.HashToken(secret),
+		Scopes:      scopes,
+		CreatedByID: creatorID,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, prefix + "_" + secret, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context) ([]domain.APIKey, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Revoke(ctx, id)
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, plainKey string) (*domain.APIKey, error) {
+	prefix, secret, ok := splitAPIKey(plainKey)
+	if !ok {
+		return nil, domain.ErrUnauthorized
+	}
+
+	key, err := s.repo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(key.KeyHash)) != 1 {
+		return nil, domain.ErrUnauthorized
+	}
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) || key.CreatedBy == nil || key.CreatedBy.DisabledAt != nil {
+		return nil, domain.ErrUnauthorized
+	}
+
+	s.touch(ctx, key.ID, now)
+	return key, nil
+}
+
+// validateScopes removes duplicates and rejects scopes that aren't permissions the creator holds,
+// so a key can never do more than the admin who created it
+func (s *apiKeyService) validateScopes(ctx context.Context, creator *domain.User, scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, domain.ErrInvalidScope
+	}
+
+	roleID := uuid.Nil
+	if creator.RoleID != nil {
+		roleID = *creator.RoleID
+	}
+	granted, err := s.rbacService.GetPermissions(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if _, ok := granted[scope]; !ok {
+			return nil, domain.ErrInvalidScope
+		}
+		if _, dup := seen[scope]; dup {
+			continue
+		}
+		seen[scope] = struct{}{}
+		result = append(result, scope)
+	}
+	return result, nil
+}
+
+// touch records key usage, at most once per apiKeyLastUsedInterval
+func (s *apiKeyService) touch(ctx context.Context, id uuid.UUID, now time.Time) {
+	s.mu.Lock()
+	last, ok := s.lastUsed[id]
+	if ok && now.Sub(last) < apiKeyLastUsedInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastUsed[id] = now
+	s.mu.Unlock()
+
+	if err := s.repo.UpdateLastUsed(ctx, id, now); err != nil {
+		log.Printf("Failed to update last_used_at of API key %s: %v", id, err)
+	}
+}
+
+func splitAPIKey(plainKey string) (prefix, secret string, ok bool) {
+	if !strings.HasPrefix(plainKey, domain.APIKeyPrefix) {
+		return "", "", false
+	}
+	prefixLen := len(domain.APIKeyPrefix) + apiKeyPrefixLength
+	if len(plainKey) <= prefixLen+1 || plainKey[prefixLen] != '_' {
+		return "", "", false
+	}
+	return plainKey[:prefixLen], plainKey[prefixLen+1:], true
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+// auditIgnoredFields change on every update and would only add noise to the diff
+var auditIgnoredFields = map[string]struct{}{
+	"updated_at": {},
+}
+
+type auditLogger struct {
+	repo domain.AuditEventRepository
+}
+
+func NewAuditLogger(repo domain.AuditEventRepository) domain.AuditLogger {
+	return &auditLogger{repo: repo}
+}
+
+func (l *auditLogger) Record(ctx context.Context, entry domain.AuditEntry) {
+	event := &domain.AuditEvent{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		IPAddress:  entry.Client.IPAddress,
+		UserAgent:  entry.Client.UserAgent,
+		Metadata:   entry.Metadata,
+	}
+	if entry.ActorID != uuid.Nil {
+		event.ActorID = &entry.ActorID
+	}
+	if entry.APIKeyID != uuid.Nil {
+		event.APIKeyID = &entry.APIKeyID
+	}
+
+	changes, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		// Still worth recording who did what
+		log.Printf("Failed to diff audit event %s: %v", entry.Action, err)
+	}
+	event.Changes = changes
+
+	if err := l.repo.Create(ctx, event); err != nil {
+		log.Printf("Failed to write audit event %s on %s %s: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
+
+func (l *auditLogger) FindEvents(ctx context.Context, params domain.AuditQueryParams) ([]domain.AuditEvent, int64, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	if params.ActorID != "" {
+		if _, err := uuid.Parse(params.ActorID); err != nil {
+			return nil, 0, domain.ErrBadParamInput
+		}
+	}
+	if params.From != nil && params.To != nil && !params.From.Before(*params.To) {
+		return nil, 0, domain.ErrBadParamInput
+	}
+	return l.repo.FindAll(ctx, params)
+}
+
+// auditDiff compares the JSON form of two entities field by field, so the log shows exactly
+// what an API client would have seen change. Fields hidden from JSON (password hashes) never appear.
+func auditDiff(before, after interface{}) (map[string]domain.AuditChange, error) {
+	if before == nil && after == nil {
+		return nil, nil
+	}
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]domain.AuditChange)
+	for name, value := range beforeFields {
+		if _, ignored := auditIgnoredFields[name]; ignored {
+			continue
+		}
+		if newValue, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[name] = domain.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ignored := auditIgnoredFields[name]; ignored {
+			continue
+		}
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = domain.AuditChange{After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil || (reflect.ValueOf(entity).Kind() == reflect.Ptr && reflect.ValueOf(entity).IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
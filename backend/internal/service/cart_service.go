@@ -2,32 +2,233 @@ package service
 
 import (
 	"context"
-	"errors"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
 	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
 )
 
 type cartService struct {
-	repo        domain.CartRepository
-	productRepo domain.ProductRepository
+	repo           domain.CartRepository
+	productRepo    domain.ProductRepository
+	pricingService domain.PricingService
+	config         *config.Config
 }
 
 type CartService interface {
-	GetCart(ctx context.Context, userID uuid.UUID) (*domain.Cart, error)
-	AddToCart(ctx context.Context, userID uuid.UUID, req domain.AddToCartRequest) error
-	UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req domain.UpdateCartItemRequest) error
-	RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+	// GetCart sets each item product's EffectivePrice for the owner's customer group
+	GetCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error)
+	// AddToCart returns the cart the item went into, for guests it may have just been created
+	AddToCart(ctx context.Context, owner domain.CartOwner, req domain.AddToCartRequest) (*domain.Cart, error)
+	UpdateItem(ctx context.Context, owner domain.CartOwner, itemID uuid.UUID, req domain.UpdateCartItemRequest) error
+	RemoveItem(ctx context.Context, owner domain.CartOwner, itemID uuid.UUID) error
+	// MergeGuestCart moves a guest cart into the user's cart after login or registration
+	MergeGuestCart(ctx context.Context, userID, guestCartID uuid.UUID) error
+	// StartGuestCartCleanup deletes guest carts whose cookie has expired every interval
+	StartGuestCartCleanup(interval time.Duration)
 }
 
-func NewCartService(repo domain.CartRepository, productRepo domain.ProductRepository) CartService {
+func NewCartService(repo domain.CartRepository, productRepo domain.ProductRepository, pricingService domain.PricingService, cfg *config.Config) CartService {
 	return &cartService{
-		repo:        repo,
-		productRepo: productRepo,
+		repo:           repo,
+		productRepo:    productRepo,
+		pricingService: pricingService,
+		config:         cfg,
 	}
 }
 
-func (s *cartService) GetCart(ctx context.Context, userID uuid.UUID) (*domain.Cart, error) {
+func (s *cartService) GetCart(ctx context.Context, owner domain.CartOwner) (*domain.Cart, error) {
+	var cart *domain.Cart
+	var err error
+	if owner.IsGuest() {
+		cart, err = s.findGuestCart(ctx, owner.GuestCartID)
+		if err != nil {
+			return nil, err
+		}
+		if cart == nil {
+			// Not saved, guests only get a cart row once they add something
+			return &domain.Cart{Items: []domain.CartItem{}}, nil
+		}
+	} else {
+		cart, err = s.getUserCart(ctx, owner.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Guests have no customer group, owner.UserID is uuid.Nil for them
+	priceList, err := s.pricingService.PriceListFor(ctx, owner.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		priceList.Apply(&item.Product)
+		if item.Variant != nil {
+			item.Variant.EffectivePrice = priceList.VariantPrice(&item.Product, item.Variant)
+		}
+	}
+	return cart, nil
+}
+
+func (s *cartService) AddToCart(ctx context.Context, owner domain.CartOwner, req domain.AddToCartRequest) (*domain.Cart, error) {
+	// 1. Validate Product (and Variant) & Stock
+	product, err := s.productRepo.FindListedByID(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	stock := product.Stock
+	if len(product.Variants) > 0 {
+		if req.VariantID == nil {
+			return nil, domain.ErrVariantRequired
+		}
+		variant := findVariant(product, *req.VariantID)
+		if variant == nil {
+			return nil, domain.ErrNotFound
+		}
+		stock = variant.Stock
+	} else if req.VariantID != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	// 2. Get Cart, adding to a line already in it can't go beyond the stock either
+	var cart *domain.Cart
+	if owner.IsGuest() {
+		cart, err = s.findGuestCart(ctx, owner.GuestCartID)
+	} else {
+		cart, err = s.getUserCart(ctx, owner.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	inCart := 0
+	if cart != nil {
+		line := (&domain.CartItem{ProductID: product.ID, VariantID: req.VariantID}).Line()
+		for i := range cart.Items {
+			if cart.Items[i].Line() == line {
+				inCart = cart.Items[i].Quantity
+			}
+		}
+	}
+	if inCart+req.Quantity > stock {
+		return nil, domain.ErrInsufficientStock
+	}
+
+	// Guests only get a cart row once they add something
+	if cart == nil {
+		cart = &domain.Cart{}
+		if err := s.repo.Create(ctx, cart); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. Add Item
+	item := &domain.CartItem{
+		CartID:    cart.ID,
+		ProductID: product.ID,
+		VariantID: req.VariantID,
+		Quantity:  req.Quantity,
+	}
+	if err := s.repo.AddItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (s *cartService) UpdateItem(ctx context.Context, owner domain.CartOwner, itemID uuid.UUID, req domain.UpdateCartItemRequest) error {
+	item, err := s.findItem(ctx, owner, itemID)
+	if err != nil {
+		return err
+	}
+
+	stock := item.Product.Stock
+	if item.Variant != nil {
+		stock = item.Variant.Stock
+	}
+	if req.Quantity > stock {
+		return domain.ErrInsufficientStock
+	}
+
+	// Not the loaded item, Save would also write the preloaded product
+	return s.repo.UpdateItem(ctx, &domain.CartItem{
+		ID:        item.ID,
+		CartID:    item.CartID,
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  req.Quantity,
+		CreatedAt: item.CreatedAt,
+	})
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, owner domain.CartOwner, itemID uuid.UUID) error {
+	item, err := s.findItem(ctx, owner, itemID)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveItem(ctx, item.ID)
+}
+
+func (s *cartService) MergeGuestCart(ctx context.Context, userID, guestCartID uuid.UUID) error {
+	guestCart, err := s.findGuestCart(ctx, guestCartID)
+	if err != nil || guestCart == nil {
+		return err
+	}
+	userCart, err := s.getUserCart(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[domain.CartLine]int, len(userCart.Items))
+	for _, item := range userCart.Items {
+		existing[item.Line()] = item.Quantity
+	}
+
+	// Products (or variants) in both carts are added up, but never beyond the stock. Sold out or
+	// deleted products are dropped, the user's own quantities are left as they are.
+	quantities := make(map[domain.CartLine]int, len(guestCart.Items))
+	for _, item := range guestCart.Items {
+		if item.Product.ID == uuid.Nil {
+			continue
+		}
+		stock := item.Product.Stock
+		if item.Variant != nil {
+			stock = item.Variant.Stock
+		}
+		line := item.Line()
+		quantity := existing[line] + item.Quantity
+		if quantity > stock {
+			quantity = stock
+		}
+		if quantity > existing[line] {
+			quantities[line] = quantity
+		}
+	}
+
+	return s.repo.MergeCart(ctx, guestCart.ID, userCart.ID, quantities)
+}
+
+func (s *cartService) StartGuestCartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			// The cookie is issued when the cart is created, after its TTL nobody can reach the cart anymore
+			cutoff := time.Now().Add(-utils.GuestCartTTL(s.config))
+			deleted, err := s.repo.DeleteGuestCarts(context.Background(), cutoff)
+			if err != nil {
+				log.Printf("Failed to delete expired guest carts: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Deleted %d expired guest carts", deleted)
+			}
+		}
+	}()
+}
+
+// getUserCart returns the user's cart, creating it on first use
+func (s *cartService) getUserCart(ctx context.Context, userID uuid.UUID) (*domain.Cart, error) {
 	cart, err := s.repo.FindBytesUserID(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -35,7 +236,7 @@ func (s *cartService) GetCart(ctx context.Context, userID uuid.UUID) (*domain.Ca
 	if cart == nil {
 		// Create new cart for user
 		newCart := &domain.Cart{
-			UserID: userID,
+			UserID: &userID,
 		}
 		if err := s.repo.Create(ctx, newCart); err != nil {
 			return nil, err
@@ -45,46 +246,47 @@ func (s *cartService) GetCart(ctx context.Context, userID uuid.UUID) (*domain.Ca
 	return cart, nil
 }
 
-func (s *cartService) AddToCart(ctx context.Context, userID uuid.UUID, req domain.AddToCartRequest) error {
-    // 1. Get or Create Cart
-    cart, err := s.GetCart(ctx, userID)
-    if err != nil {
-        return err
-    }
-
-    // 2. Validate Product & Stock
-    product, err := s.productRepo.FindByID(ctx, req.ProductID)
-    if err != nil {
-        return err
-    }
-    if product.Stock < req.Quantity {
-        return errors.New("insufficient stock")
-    }
-
-    // 3. Add Item
-    item := &domain.CartItem{
-        CartID:    cart.ID,
-        ProductID: product.ID,
-        Quantity:  req.Quantity,
-    }
-
-    return s.repo.AddItem(ctx, item)
+// findGuestCart returns nil when there is no such guest cart, e.g. it was merged at login
+func (s *cartService) findGuestCart(ctx context.Context, cartID uuid.UUID) (*domain.Cart, error) {
+	if cartID == uuid.Nil {
+		return nil, nil
+	}
+	cart, err := s.repo.FindByID(ctx, cartID)
+	if err != nil || cart == nil || cart.UserID != nil {
+		return nil, err
+	}
+	return cart, nil
 }
 
-func (s *cartService) UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, req domain.UpdateCartItemRequest) error {
-	// Ideally we should verify the item belongs to the user's cart
-    // For MVP, we trust the repo logic or could add a check
-    // Since we don't have GetItemByID separate from Cart, we rely on Repo/DB constraints or we fetch Cart first.
-    // Let's rely on Repo for update, but we really should ensure ownership.
-    // Simpler: Just update for now, standard security check later.
-    
-    item := &domain.CartItem{
-        ID: itemID,
-        Quantity: req.Quantity,
-    }
-    return s.repo.UpdateItem(ctx, item)
+// findItem returns the item if it is in the owner's cart
+func (s *cartService) findItem(ctx context.Context, owner domain.CartOwner, itemID uuid.UUID) (*domain.CartItem, error) {
+	var cart *domain.Cart
+	var err error
+	if owner.IsGuest() {
+		cart, err = s.findGuestCart(ctx, owner.GuestCartID)
+	} else {
+		cart, err = s.repo.FindBytesUserID(ctx, owner.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cart == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	for i := range cart.Items {
+		if cart.Items[i].ID == itemID {
+			return &cart.Items[i], nil
+		}
+	}
+	return nil, domain.ErrNotFound
 }
 
-func (s *cartService) RemoveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
-    return s.repo.RemoveItem(ctx, itemID)
+func findVariant(product *domain.Product, variantID uuid.UUID) *domain.ProductVariant {
+	for i := range product.Variants {
+		if product.Variants[i].ID == variantID {
+			return &product.Variants[i]
+		}
+	}
+	return nil
 }
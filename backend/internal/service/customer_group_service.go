@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type customerGroupService struct {
+	groupRepo    domain.CustomerGroupRepository
+	userRepo     domain.UserRepository
+	productRepo  domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+}
+
+func NewCustomerGroupService(groupRepo domain.CustomerGroupRepository, userRepo domain.UserRepository, productRepo domain.ProductRepository, categoryRepo domain.CategoryRepository) domain.CustomerGroupService {
+	return &customerGroupService{
+		groupRepo:    groupRepo,
+		userRepo:     userRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *customerGroupService) ListGroups(ctx context.Context) ([]domain.CustomerGroup, error) {
+	return s.groupRepo.FindAll(ctx)
+}
+
+func (s *customerGroupService) GetGroup(ctx context.Context, id uuid.UUID) (*domain.CustomerGroup, error) {
+	return s.groupRepo.FindByID(ctx, id)
+}
+
+func (s *customerGroupService) CreateGroup(ctx context.Context, req domain.CustomerGroupRequest) (*domain.CustomerGroup, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	if existing, _ := s.groupRepo.FindByName(ctx, name); existing != nil {
+		return nil, domain.ErrConflict
+	}
+
+	group := &domain.CustomerGroup{
+		Name:        name,
+		Description: req.Description,
+	}
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *customerGroupService) UpdateGroup(ctx context.Context, id uuid.UUID, req domain.CustomerGroupRequest) (*domain.CustomerGroup, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	if name != group.Name {
+		if existing, _ := s.groupRepo.FindByName(ctx, name); existing != nil {
+			return nil, domain.ErrConflict
+		}
+	}
+
+	group.Name = name
+	group.Description = req.Description
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (s *customerGroupService) DeleteGroup(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.groupRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	count, err := s.userRepo.CountByCustomerGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrGroupInUse
+	}
+
+	return s.groupRepo.Delete(ctx, id)
+}
+
+func (s *customerGroupService) SetPriceRule(ctx context.Context, groupID uuid.UUID, req domain.PriceRuleRequest) (*domain.CustomerGroup, error) {
+	if _, err := s.groupRepo.FindByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	if err := validatePriceRule(req); err != nil {
+		return nil, err
+	}
+
+	// The target has to exist, otherwise the rule silently never applies
+	if req.ProductID != nil {
+		if _, err := s.productRepo.FindByID(ctx, *req.ProductID); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := s.categoryRepo.FindByID(ctx, *req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &domain.GroupPriceRule{
+		GroupID:         groupID,
+		ProductID:       req.ProductID,
+		CategoryID:      req.CategoryID,
+		Price:           req.Price,
+		DiscountPercent: req.DiscountPercent,
+	}
+	if err := s.groupRepo.SavePriceRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.FindByID(ctx, groupID)
+}
+
+func (s *customerGroupService) DeletePriceRule(ctx context.Context, groupID, ruleID uuid.UUID) (*domain.CustomerGroup, error) {
+	if err := s.groupRepo.DeletePriceRule(ctx, groupID, ruleID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.FindByID(ctx, groupID)
+}
+
+func (s *customerGroupService) AssignUser(ctx context.Context, userID uuid.UUID, groupID *uuid.UUID) (*domain.User, error) {
+	if groupID != nil {
+		if _, err := s.groupRepo.FindByID(ctx, *groupID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.userRepo.UpdateCustomerGroup(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+func validatePriceRule(req domain.PriceRuleRequest) error {
+	if (req.ProductID == nil) == (req.CategoryID == nil) {
+		return domain.ErrInvalidPriceRule
+	}
+	if (req.Price == nil) == (req.DiscountPercent == nil) {
+		return domain.ErrInvalidPriceRule
+	}
+	if req.Price != nil && (req.ProductID == nil || *req.Price <= 0) {
+		return domain.ErrInvalidPriceRule
+	}
+	if req.DiscountPercent != nil && (*req.DiscountPercent <= 0 || *req.DiscountPercent >= 100) {
+		return domain.ErrInvalidPriceRule
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type loginThrottler struct {
+	store           domain.LoginAttemptStore
+	maxAccountFails int
+	maxIPFails      int
+	window          time.Duration
+	lockoutBase     time.Duration
+	lockoutMax      time.Duration
+}
+
+func NewLoginThrottler(store domain.LoginAttemptStore, cfg *config.Config) domain.LoginThrottler {
+	return &loginThrottler{
+		store:           store,
+		maxAccountFails: cfg.Auth.LoginMaxAccountFailures,
+		maxIPFails:      cfg.Auth.LoginMaxIPFailures,
+		window:          utils.ParseDurationOrDefault(cfg.Auth.LoginFailureWindow, 15*time.Minute),
+		lockoutBase:     utils.ParseDurationOrDefault(cfg.Auth.LoginLockoutBase, time.Minute),
+		lockoutMax:      utils.ParseDurationOrDefault(cfg.Auth.LoginLockoutMax, time.Hour),
+	}
+}
+
+// pendingTTL frees the attempts held by requests that never reported back (e.g. a crash)
+const pendingTTL = time.Minute
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// pendingKey counts the attempts Check let through that haven't failed or succeeded yet
+func pendingKey(key string) string {
+	return "pending:" + key
+}
+
+type loginLimit struct {
+	key       string
+	threshold int
+}
+
+// limits returns the enabled limits, a threshold <= 0 disables one
+func (t *loginThrottler) limits(email, ip string) []loginLimit {
+	var limits []loginLimit
+	if t.maxAccountFails > 0 {
+		limits = append(limits, loginLimit{accountKey(email), t.maxAccountFails})
+	}
+	if t.maxIPFails > 0 {
+		limits = append(limits, loginLimit{ipKey(ip), t.maxIPFails})
+	}
+	return limits
+}
+
+func (t *loginThrottler) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	limits := t.limits(email, ip)
+
+	// Hold one of the attempts left before reading the counters, the password check runs outside
+	// of the store. Every concurrent attempt is then counted as pending, as a failure, or both.
+	pending := make([]int, len(limits))
+	for i, limit := range limits {
+		held, err := t.store.Increment(ctx, pendingKey(limit.key), pendingTTL)
+		if err != nil {
+			return errors.Join(err, t.release(ctx, limits[:i]))
+		}
+		pending[i] = held.Failures
+	}
+
+	var retryAfter time.Duration
+	for i, limit := range limits {
+		attempt, err := t.store.Get(ctx, limit.key)
+		if err != nil {
+			return errors.Join(err, t.release(ctx, limits))
+		}
+		failures := 0
+		if attempt != nil {
+			retryAfter = max(retryAfter, attempt.LockedUntil.Sub(now))
+			// Same rule as the store's Increment: an ended window starts over on the next failure
+			if now.Sub(attempt.WindowStart) <= t.window {
+				failures = attempt.Failures
+			}
+		}
+		// The attempts in flight will either lock the key or free their slot shortly
+		if pending[i] > max(1, limit.threshold-failures) {
+			retryAfter = max(retryAfter, time.Second)
+		}
+	}
+
+	if retryAfter > 0 {
+		if err := t.release(ctx, limits); err != nil {
+			return err
+		}
+		return &domain.LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (t *loginThrottler) RegisterFailure(ctx context.Context, email, ip string) error {
+	var err error
+	for _, limit := range t.limits(email, ip) {
+		if err = t.registerFailure(ctx, limit); err != nil {
+			break
+		}
+	}
+	// Released once the failure is counted, so a concurrent Check always sees one of them
+	return errors.Join(err, t.Release(ctx, email, ip))
+}
+
+func (t *loginThrottler) RegisterSuccess(ctx context.Context, email, ip string) error {
+	// The IP counter is left alone so valid logins can't be interleaved to reset it
+	return errors.Join(t.store.Delete(ctx, accountKey(email)), t.Release(ctx, email, ip))
+}
+
+func (t *loginThrottler) Release(ctx context.Context, email, ip string) error {
+	return t.release(ctx, t.limits(email, ip))
+}
+
+func (t *loginThrottler) Unlock(ctx context.Context, email string) error {
+	return t.store.Delete(ctx, accountKey(email))
+}
+
+func (t *loginThrottler) release(ctx context.Context, limits []loginLimit) error {
+	var errs []error
+	for _, limit := range limits {
+		errs = append(errs, t.store.Decrement(ctx, pendingKey(limit.key)))
+	}
+	return errors.Join(errs...)
+}
+
+func (t *loginThrottler) registerFailure(ctx context.Context, limit loginLimit) error {
+	attempt, err := t.store.Increment(ctx, limit.key, t.window)
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < limit.threshold {
+		return nil
+	}
+
+	lockout := t.lockoutFor(attempt.Failures - limit.threshold)
+	return t.store.Lock(ctx, limit.key, time.Now().Add(lockout), lockout+t.window)
+}
+
+// lockoutFor is the progressive lockout after the given number of failures past the
+// threshold: base, 2x base, 4x base, ... capped at lockoutMax
+func (t *loginThrottler) lockoutFor(extraFailures int) time.Duration {
+	lockout := t.lockoutBase
+	for i := 0; i < extraFailures && lockout < t.lockoutMax; i++ {
+		lockout *= 2
+	}
+	return min(lockout, t.lockoutMax)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/internal/repository"
+)
+
+func newTestLoginThrottler(maxAccountFailures, maxIPFailures int) domain.LoginThrottler {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		LoginMaxAccountFailures: maxAccountFailures,
+		LoginMaxIPFailures:      maxIPFailures,
+		LoginFailureWindow:      "15m",
+		LoginLockoutBase:        "1m",
+		LoginLockoutMax:         "5m",
+	}}
+	return NewLoginThrottler(repository.NewMemoryLoginAttemptStore(), cfg)
+}
+
+// lockout returns how long Check keeps the account and IP locked, 0 when it lets them through
+func lockout(t *testing.T, throttler domain.LoginThrottler, email, ip string) time.Duration {
+	t.Helper()
+	err := throttler.Check(context.Background(), email, ip)
+	if err == nil {
+		return 0
+	}
+	var locked *domain.LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Check error = %v, want a *LockedError", err)
+	}
+	return locked.RetryAfter
+}
+
+func TestLoginThrottlerLockoutDuration(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{2, 0},
+		{3, time.Minute}, // Threshold
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 5 * time.Minute}, // Capped at the max
+		{10, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d failures", tt.failures), func(t *testing.T) {
+			throttler := newTestLoginThrottler(3, 100)
+			for i := 0; i < tt.failures; i++ {
+				if err := throttler.RegisterFailure(context.Background(), "user@example.com", "10.0.0.1"); err != nil {
+					t.Fatalf("RegisterFailure: %v", err)
+				}
+			}
+
+			got := lockout(t, throttler, "user@example.com", "10.0.0.1")
+			if got > tt.want || got < tt.want-time.Second {
+				t.Errorf("after %d failures locked for %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginThrottlerKeys(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                string
+		maxAccount, maxIP   int
+		run                 func(t *testing.T, throttler domain.LoginThrottler)
+		checkEmail, checkIP string
+		wantLocked          bool
+	}{
+		{
+			name: "account is locked from every IP", maxAccount: 2, maxIP: 100,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.2")
+			},
+			checkEmail: "user@example.com", checkIP: "10.0.0.3", wantLocked: true,
+		},
+		{
+			name: "emails are compared case-insensitively", maxAccount: 2, maxIP: 100,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "User@Example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, " user@example.com", "10.0.0.1")
+			},
+			checkEmail: "USER@example.com", checkIP: "10.0.0.2", wantLocked: true,
+		},
+		{
+			name: "IP is locked for every account", maxAccount: 100, maxIP: 2,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "a@example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, "b@example.com", "10.0.0.1")
+			},
+			checkEmail: "c@example.com", checkIP: "10.0.0.1", wantLocked: true,
+		},
+		{
+			name: "other accounts and IPs stay open", maxAccount: 2, maxIP: 2,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+			},
+			checkEmail: "other@example.com", checkIP: "10.0.0.2",
+		},
+		{
+			name: "success resets the account", maxAccount: 2, maxIP: 100,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.RegisterSuccess(ctx, "user@example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+			},
+			checkEmail: "user@example.com", checkIP: "10.0.0.1",
+		},
+		{
+			name: "success doesn't reset the IP", maxAccount: 100, maxIP: 2,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "a@example.com", "10.0.0.1")
+				throttler.RegisterSuccess(ctx, "b@example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, "a@example.com", "10.0.0.1")
+			},
+			checkEmail: "b@example.com", checkIP: "10.0.0.1", wantLocked: true,
+		},
+		{
+			name: "unlock lifts the account lockout", maxAccount: 2, maxIP: 100,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.Unlock(ctx, "user@example.com")
+			},
+			checkEmail: "user@example.com", checkIP: "10.0.0.1",
+		},
+		{
+			name: "checks in flight hold the attempts left", maxAccount: 2, maxIP: 100,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.Check(ctx, "user@example.com", "10.0.0.1")
+			},
+			checkEmail: "user@example.com", checkIP: "10.0.0.2", wantLocked: true,
+		},
+		{
+			name: "release gives the attempt back", maxAccount: 2, maxIP: 100,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				throttler.Check(ctx, "user@example.com", "10.0.0.1")
+				throttler.Release(ctx, "user@example.com", "10.0.0.1")
+			},
+			checkEmail: "user@example.com", checkIP: "10.0.0.2",
+		},
+		{
+			name: "zero threshold disables the limit", maxAccount: 0, maxIP: 0,
+			run: func(t *testing.T, throttler domain.LoginThrottler) {
+				for i := 0; i < 10; i++ {
+					throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1")
+				}
+			},
+			checkEmail: "user@example.com", checkIP: "10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			throttler := newTestLoginThrottler(tt.maxAccount, tt.maxIP)
+			tt.run(t, throttler)
+
+			if got := lockout(t, throttler, tt.checkEmail, tt.checkIP) > 0; got != tt.wantLocked {
+				t.Errorf("locked = %v, want %v", got, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestLoginThrottlerConcurrentFailures(t *testing.T) {
+	const threshold = 5
+	ctx := context.Background()
+	throttler := newTestLoginThrottler(threshold, 1000)
+
+	// Every attempt fails, like a password guesser firing requests in parallel
+	var passed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := throttler.Check(ctx, "user@example.com", "10.0.0.1")
+				var locked *domain.LockedError
+				switch {
+				case err == nil:
+					passed.Add(1)
+					time.Sleep(time.Millisecond) // The password check
+					if err := throttler.RegisterFailure(ctx, "user@example.com", "10.0.0.1"); err != nil {
+						t.Errorf("RegisterFailure: %v", err)
+						return
+					}
+				case errors.As(err, &locked) && locked.RetryAfter <= time.Second:
+					runtime.Gosched() // Waiting for the attempts in flight
+				default:
+					return // Locked out
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := passed.Load(); got != threshold {
+		t.Errorf("%d attempts got past Check, want %d", got, threshold)
+	}
+	if got := lockout(t, throttler, "user@example.com", "10.0.0.2"); got <= time.Second {
+		t.Errorf("locked for %v after concurrent failures, want the lockout", got)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type oidcService struct {
+	providers   map[string]domain.OIDCProvider
+	userService domain.UserService
+}
+
+func NewOIDCService(providers map[string]domain.OIDCProvider, userService domain.UserService) domain.OIDCService {
+	return &oidcService{
+		providers:   providers,
+		userService: userService,
+	}
+}
+
+func (s *oidcService) Begin(ctx context.Context, providerName string) (string, *domain.OIDCAuthRequest, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", nil, domain.ErrUnknownProvider
+	}
+
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, domain.ErrInternalServerError
+	}
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, domain.ErrInternalServerError
+	}
+	// 43 URL-safe characters, a valid PKCE code verifier
+	verifier, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, domain.ErrInternalServerError
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, utils.PKCEChallenge(verifier))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return authURL, &domain.OIDCAuthRequest{
+		Provider:     providerName,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	}, nil
+}
+
+func (s *oidcService) Complete(ctx context.Context, req *domain.OIDCAuthRequest, state, code string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	provider, ok := s.providers[req.Provider]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+
+	// The state ties the callback to the browser that started the login (CSRF protection)
+	if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(req.State)) != 1 {
+		return nil, domain.ErrInvalidToken
+	}
+
+	identity, err := provider.Exchange(ctx, code, req.CodeVerifier, req.Nonce)
+	if err != nil {
+		log.Printf("OIDC login with %s failed: %v", req.Provider, err)
+		return nil, domain.ErrUnauthorized
+	}
+
+	return s.userService.LoginWithIdentity(ctx, *identity, client)
+}
@@ -5,16 +5,20 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
 	"github.com/user/go-ecommerce/internal/domain"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type orderService struct {
-	repo        domain.OrderRepository
-	cartRepo    domain.CartRepository
-	productRepo domain.ProductRepository
-	db          *gorm.DB // Needed for transaction
+	repo           domain.OrderRepository
+	cartRepo       domain.CartRepository
+	productRepo    domain.ProductRepository
+	userRepo       domain.UserRepository
+	pricingService domain.PricingService
+	db             *gorm.DB // Needed for transaction
+	config         *config.Config
 }
 
 type OrderService interface {
@@ -23,16 +27,30 @@ type OrderService interface {
 	GetAllOrders(ctx context.Context) ([]domain.Order, error)
 }
 
-func NewOrderService(repo domain.OrderRepository, cartRepo domain.CartRepository, productRepo domain.ProductRepository, db *gorm.DB) OrderService {
+func NewOrderService(repo domain.OrderRepository, cartRepo domain.CartRepository, productRepo domain.ProductRepository, userRepo domain.UserRepository, pricingService domain.PricingService, db *gorm.DB, cfg *config.Config) OrderService {
 	return &orderService{
-		repo:        repo,
-		cartRepo:    cartRepo,
-		productRepo: productRepo,
-		db:          db,
+		repo:           repo,
+		cartRepo:       cartRepo,
+		productRepo:    productRepo,
+		userRepo:       userRepo,
+		pricingService: pricingService,
+		db:             db,
+		config:         cfg,
 	}
 }
 
 func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID) (*domain.Order, error) {
+	// 0. Policy: unverified accounts may not place orders
+	if s.config.Auth.RequireVerifiedEmailForCheckout {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user.VerifiedAt == nil {
+			return nil, domain.ErrEmailNotVerified
+		}
+	}
+
 	// 1. Get Cart
 	cart, err := s.cartRepo.FindBytesUserID(ctx, userID)
 	if err != nil {
@@ -42,50 +60,15 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID) (*domain.
 		return nil, errors.New("cart is empty")
 	}
 
-	// Start Transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	if tx.Error != nil {
-		return nil, tx.Error
+	// Resolved before locking any rows, the group's prices apply to the whole order
+	priceList, err := s.pricingService.PriceListFor(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Use tx context for all DB operations within transaction
-	// txCtx := context.WithValue(ctx, "tx", tx) 
-	// we would typically use `tx` to create new instances of repos. 
-	// However, since my repos store `db *gorm.DB`, I can't easily swap it per request unless I refactor.
-	//
-	// Workaround for MVP: Direct manipulation or updating Repos to accept Transaction injection.
-	// BETTER APPROACH for MVP:
-	// Use the `tx` object directly for updates here inside Service, OR
-	// assume optimistic locking isn't critical for MVP and just do operations.
-	// BUT, Stock Deduction MUST be atomic.
-	//
-	// Let's use `tx` to re-instantiate temporary repositories for this operation.
-	// This is the cleanest way without changing global architecture.
-
-	// Actually, best practice with current structure: 
-	// passing `tx` to repo methods? No, interfaces don't have it.
-	//
-	// Quick fix: Do the logic directly here or rely on the fact that for MVP we might skip strict ACID if hard.
-	// BUT Tokopedia quality needs ACID.
-	//
-	// Let's modify Respositories? No, too many files.
-	// Let's use `db.Transaction` closure which passes `tx`.
-	// And we need repositories to use THAT `tx`.
-	//
-	// If I cannot inject `tx` into existing simple repos, I have to execute raw GORM calls on `tx` here.
-	// Or, I define a helper `WithTx(tx *gorm.DB) Repository` on the repo interface (Advanced).
-	//
-	// FASTEST VALID PATH:
-	// Do logic inline with `tx`.
-
+	// 2. Deduct stock, create the order and clear the cart in one transaction
 	var order *domain.Order
-	
+
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		var totalAmount float64
 		var orderItems []domain.OrderItem
@@ -94,26 +77,70 @@ func (s *orderService) Checkout(ctx context.Context, userID uuid.UUID) (*domain.
 			// Lock User Product Row? (Optional)
 			var product domain.Product
 			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, cartItem.ProductID).Error; err != nil {
-				return err 
+				return err
+			}
+			// The shop may have been suspended since the product was added to the cart
+			if product.ShopID != nil {
+				var approved int64
+				if err := tx.Model(&domain.Shop{}).Where("id = ? AND status = ?", *product.ShopID, domain.ShopStatusApproved).Count(&approved).Error; err != nil {
+					return err
+				}
+				if approved == 0 {
+					return errors.New("product is no longer available: " + product.Name)
+				}
+			}
+
+			price := priceList.Price(&product)
+			orderItem := domain.OrderItem{
+				ProductID: product.ID,
+				Quantity:  cartItem.Quantity,
+			}
+
+			if cartItem.VariantID != nil {
+				// Lock the variant too, its stock is what's being sold
+				var variant domain.ProductVariant
+				if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+					Where("id = ? AND product_id = ?", *cartItem.VariantID, product.ID).
+					First(&variant).Error; err != nil {
+					return err
+				}
+				if variant.Stock < cartItem.Quantity {
+					return errors.New("insufficient stock for product: " + product.Name + " (" + variant.Title + ")")
+				}
+				variant.Stock -= cartItem.Quantity
+				if err := tx.Model(&variant).Update("stock", variant.Stock).Error; err != nil {
+					return err
+				}
+
+				price = priceList.VariantPrice(&product, &variant)
+				orderItem.VariantID = &variant.ID
+				orderItem.SKU = variant.SKU
+				orderItem.VariantTitle = variant.Title
+			} else {
+				// Added before the product got variants, the customer has to pick one
+				var variants int64
+				if err := tx.Model(&domain.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variants).Error; err != nil {
+					return err
+				}
+				if variants > 0 {
+					return errors.New("choose a variant for product: " + product.Name)
+				}
 			}
 
 			if product.Stock < cartItem.Quantity {
 				return errors.New("insufficient stock for product: " + product.Name)
 			}
 
-			// Deduct Stock
+			// Deduct Stock, for products with variants it stays the sum of theirs
 			product.Stock -= cartItem.Quantity
 			if err := tx.Save(&product).Error; err != nil {
 				return err
 			}
 
 			// Prepare Order Item
-			totalAmount += float64(cartItem.Quantity) * product.Price
-			orderItems = append(orderItems, domain.OrderItem{
-				ProductID: product.ID,
-				Quantity:  cartItem.Quantity,
-				Price:     product.Price,
-			})
+			totalAmount += float64(cartItem.Quantity) * price
+			orderItem.Price = price
+			orderItems = append(orderItems, orderItem)
 		}
 
 		// Create Order
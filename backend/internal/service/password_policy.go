@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+const (
+	// Hashing cost grows with the input, don't let a request spend it on megabytes
+	passwordMaxLength = 128
+	// bcrypt only looks at the first 72 bytes and refuses longer input
+	bcryptMaxBytes = 72
+	// Shorter name or email parts match too many passwords by accident
+	minPersonalPartLength = 4
+)
+
+type passwordPolicy struct {
+	minLength int
+	maxBytes  int // 0 means no byte limit
+	blocklist map[string]struct{}
+}
+
+// NewPasswordPolicy builds the policy from the built-in common password list
+// plus PASSWORD_BLOCKLIST_FILE when set
+func NewPasswordPolicy(cfg *config.Config) (domain.PasswordPolicy, error) {
+	p := &passwordPolicy{
+		minLength: cfg.Password.MinLength,
+		blocklist: make(map[string]struct{}),
+	}
+	if cfg.Password.Algorithm == utils.PasswordAlgorithmBcrypt {
+		p.maxBytes = bcryptMaxBytes
+	}
+
+	if err := p.addBlocklist(strings.NewReader(commonPasswords)); err != nil {
+		return nil, err
+	}
+	if cfg.Password.BlocklistFile != "" {
+		f, err := os.Open(cfg.Password.BlocklistFile)
+		if err != nil {
+			return nil, fmt.Errorf("open password blocklist: %w", err)
+		}
+		defer f.Close()
+		if err := p.addBlocklist(f); err != nil {
+			return nil, fmt.Errorf("read password blocklist: %w", err)
+		}
+	}
+	return p, nil
+}
+
+func (p *passwordPolicy) Validate(password string, personal ...string) error {
+	length := len([]rune(password))
+	if length < p.minLength {
+		return &domain.PasswordPolicyError{Reason: fmt.Sprintf("must be at least %d characters", p.minLength)}
+	}
+	if length > passwordMaxLength || (p.maxBytes > 0 && len(password) > p.maxBytes) {
+		return &domain.PasswordPolicyError{Reason: "is too long"}
+	}
+
+	lower := strings.ToLower(password)
+	if p.isBlocked(lower) {
+		return &domain.PasswordPolicyError{Reason: "is too common, choose a less predictable one"}
+	}
+
+	for _, value := range personal {
+		for _, part := range personalParts(value) {
+			if strings.Contains(lower, part) {
+				return &domain.PasswordPolicyError{Reason: "must not contain your name or email address"}
+			}
+		}
+	}
+	return nil
+}
+
+// isBlocked also catches list entries with digits or symbols appended, e.g. "Summer2024!"
+func (p *passwordPolicy) isBlocked(lower string) bool {
+	if _, ok := p.blocklist[lower]; ok {
+		return true
+	}
+	stem := strings.TrimRightFunc(lower, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	if stem == "" {
+		return false
+	}
+	_, ok := p.blocklist[stem]
+	return ok
+}
+
+func (p *passwordPolicy) addBlocklist(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.blocklist[strings.ToLower(line)] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// personalParts splits a name or email address into the words a password shouldn't contain
+func personalParts(value string) []string {
+	value = strings.ToLower(value)
+	if local, _, ok := strings.Cut(value, "@"); ok {
+		value = local
+	}
+
+	var parts []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(part)) >= minPersonalPartLength {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type pricingService struct {
+	userRepo  domain.UserRepository
+	groupRepo domain.CustomerGroupRepository
+}
+
+func NewPricingService(userRepo domain.UserRepository, groupRepo domain.CustomerGroupRepository) domain.PricingService {
+	return &pricingService{
+		userRepo:  userRepo,
+		groupRepo: groupRepo,
+	}
+}
+
+func (s *pricingService) PriceListFor(ctx context.Context, userID uuid.UUID) (*domain.PriceList, error) {
+	if userID == uuid.Nil {
+		return nil, nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.CustomerGroupID == nil {
+		return nil, nil
+	}
+
+	rules, err := s.groupRepo.FindPriceRules(ctx, *user.CustomerGroupID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewPriceList(rules), nil
+}
+
+func (s *pricingService) ApplyPrices(ctx context.Context, userID uuid.UUID, products ...*domain.Product) error {
+	priceList, err := s.PriceListFor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	priceList.Apply(products...)
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type fakeCustomerGroupRepository struct {
+	domain.CustomerGroupRepository
+	rules map[uuid.UUID][]domain.GroupPriceRule
+}
+
+func (r *fakeCustomerGroupRepository) FindPriceRules(ctx context.Context, groupID uuid.UUID) ([]domain.GroupPriceRule, error) {
+	return r.rules[groupID], nil
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestPricingServiceApplyPrices(t *testing.T) {
+	groupID := uuid.New()
+	categoryID := uuid.New()
+	otherCategoryID := uuid.New()
+
+	member := &domain.User{ID: uuid.New(), CustomerGroupID: &groupID}
+	noGroup := &domain.User{ID: uuid.New()}
+
+	fixed := &domain.Product{ID: uuid.New(), CategoryID: categoryID, Price: 100}
+	discounted := &domain.Product{ID: uuid.New(), CategoryID: otherCategoryID, Price: 100}
+	inCategory := &domain.Product{ID: uuid.New(), CategoryID: categoryID, Price: 19.99}
+	unruled := &domain.Product{ID: uuid.New(), CategoryID: otherCategoryID, Price: 50}
+
+	service := NewPricingService(
+		&fakeUserRepository{users: map[uuid.UUID]*domain.User{member.ID: member, noGroup.ID: noGroup}},
+		&fakeCustomerGroupRepository{rules: map[uuid.UUID][]domain.GroupPriceRule{groupID: {
+			{ProductID: &fixed.ID, Price: float(80)},
+			{ProductID: &discounted.ID, DiscountPercent: float(25)},
+			{CategoryID: &categoryID, DiscountPercent: float(10)},
+		}}},
+	)
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		product *domain.Product
+		want    float64
+	}{
+		{"guest pays base price", uuid.Nil, fixed, 100},
+		{"user without group pays base price", noGroup.ID, fixed, 100},
+		{"product price beats category discount", member.ID, fixed, 80},
+		{"product discount", member.ID, discounted, 75},
+		{"category discount rounded to cents", member.ID, inCategory, 17.99},
+		{"no rule", member.ID, unruled, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := *tt.product
+			if err := service.ApplyPrices(context.Background(), tt.userID, &product); err != nil {
+				t.Fatalf("ApplyPrices: %v", err)
+			}
+			if product.EffectivePrice != tt.want {
+				t.Errorf("EffectivePrice = %v, want %v", product.EffectivePrice, tt.want)
+			}
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		product := *fixed
+		if err := service.ApplyPrices(context.Background(), uuid.New(), &product); err != domain.ErrNotFound {
+			t.Errorf("ApplyPrices error = %v, want %v", err, domain.ErrNotFound)
+		}
+	})
+}
+
+func TestPriceListVariantPrice(t *testing.T) {
+	categoryID := uuid.New()
+	product := &domain.Product{ID: uuid.New(), CategoryID: categoryID, Price: 40}
+
+	tests := []struct {
+		name    string
+		rules   []domain.GroupPriceRule
+		variant domain.ProductVariant
+		want    float64
+	}{
+		{"no price list, product price", nil, domain.ProductVariant{}, 40},
+		{"no price list, own price", nil, domain.ProductVariant{Price: float(60)}, 60},
+		{"product discount off own price", []domain.GroupPriceRule{{ProductID: &product.ID, DiscountPercent: float(50)}}, domain.ProductVariant{Price: float(60)}, 30},
+		{"category discount off product price", []domain.GroupPriceRule{{CategoryID: &categoryID, DiscountPercent: float(10)}}, domain.ProductVariant{}, 36},
+		{"fixed product price for every variant", []domain.GroupPriceRule{{ProductID: &product.ID, Price: float(25)}}, domain.ProductVariant{Price: float(60)}, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var priceList *domain.PriceList
+			if tt.rules != nil {
+				priceList = domain.NewPriceList(tt.rules)
+			}
+			if got := priceList.VariantPrice(product, &tt.variant); got != tt.want {
+				t.Errorf("VariantPrice = %v, want %v", got, tt.want)
+			}
+
+			// Apply sets the same price on loaded variants
+			withVariant := *product
+			withVariant.Variants = []domain.ProductVariant{tt.variant}
+			priceList.Apply(&withVariant)
+			if got := withVariant.Variants[0].EffectivePrice; got != tt.want {
+				t.Errorf("Apply set EffectivePrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
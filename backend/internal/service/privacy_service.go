@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// Accounts without a password confirm a deletion by having signed in this recently
+const reauthenticationWindow = 10 * time.Minute
+
+type privacyService struct {
+	userRepo         domain.UserRepository
+	addressRepo      domain.AddressRepository
+	wishlistRepo     domain.WishlistRepository
+	cartRepo         domain.CartRepository
+	orderRepo        domain.OrderRepository
+	erasureRepo      domain.AccountErasureRepository
+	sessionService   domain.SessionService
+	twoFactorService domain.TwoFactorService
+}
+
+func NewPrivacyService(userRepo domain.UserRepository, addressRepo domain.AddressRepository, wishlistRepo domain.WishlistRepository, cartRepo domain.CartRepository, orderRepo domain.OrderRepository, erasureRepo domain.AccountErasureRepository, sessionService domain.SessionService, twoFactorService domain.TwoFactorService) domain.PrivacyService {
+	return &privacyService{
+		userRepo:         userRepo,
+		addressRepo:      addressRepo,
+		wishlistRepo:     wishlistRepo,
+		cartRepo:         cartRepo,
+		orderRepo:        orderRepo,
+		erasureRepo:      erasureRepo,
+		sessionService:   sessionService,
+		twoFactorService: twoFactorService,
+	}
+}
+
+func (s *privacyService) ExportData(ctx context.Context, userID uuid.UUID) (*domain.UserDataExport, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := s.addressRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	wishlist, err := s.wishlistRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	cart, err := s.cartRepo.FindBytesUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	orders, err := s.orderRepo.FindAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserDataExport{
+		Profile:   user,
+		Addresses: addresses,
+		Wishlist:  wishlist,
+		Cart:      cart,
+		Orders:    orders,
+	}, nil
+}
+
+func (s *privacyService) DeleteAccount(ctx context.Context, userID, sessionID uuid.UUID, req domain.DeleteAccountRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// A stolen session alone shouldn't be enough to erase the account
+	if user.Password != "" && !utils.CheckPasswordHash(req.Password, user.Password) {
+		return domain.ErrWrongPassword
+	}
+	// Provider-only accounts have no password to confirm, a 2FA code or a fresh provider login stands in for it
+	if user.Password == "" && !user.TwoFactorEnabled {
+		session, err := s.sessionService.GetSession(ctx, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.UserID != userID || time.Since(session.CreatedAt) > reauthenticationWindow {
+			return domain.ErrReauthRequired
+		}
+	}
+	if user.TwoFactorEnabled {
+		ok, err := s.twoFactorService.VerifyCode(ctx, user, req.Code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return domain.ErrInvalidCode
+		}
+	}
+
+	if user.Role != nil && user.Role.Name == domain.RoleAdmin {
+		count, err := s.userRepo.CountActiveByRole(ctx, user.Role.ID)
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return domain.ErrLastAdmin
+		}
+	}
+
+	// Revoked first so access tokens stop working right away, Anonymize then deletes the session rows
+	if err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.erasureRepo.Anonymize(ctx, userID, time.Now())
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+const maxAltTextLength = 255
+
+type productImageService struct {
+	repo        domain.ProductImageRepository
+	productRepo domain.ProductRepository
+	storage     domain.Storage
+	cfg         *config.Config
+}
+
+// ProductImageService manages a product's image gallery. Sellers go through
+// SellerHandler, which checks the product is in their shop first.
+type ProductImageService interface {
+	FindAll(ctx context.Context, productID uuid.UUID) ([]domain.ProductImage, error)
+	FindByID(ctx context.Context, productID, imageID uuid.UUID) (*domain.ProductImage, error)
+	// Upload checks the file's size and type from its content, stores it and adds it to the end of the gallery
+	Upload(ctx context.Context, productID uuid.UUID, data []byte, altText string) (*domain.ProductImage, error)
+	UpdateAltText(ctx context.Context, productID, imageID uuid.UUID, req domain.UpdateProductImageRequest) (*domain.ProductImage, error)
+	Reorder(ctx context.Context, productID uuid.UUID, req domain.ReorderProductImagesRequest) ([]domain.ProductImage, error)
+	Delete(ctx context.Context, productID, imageID uuid.UUID) error
+}
+
+func NewProductImageService(repo domain.ProductImageRepository, productRepo domain.ProductRepository, storage domain.Storage, cfg *config.Config) ProductImageService {
+	return &productImageService{
+		repo:        repo,
+		productRepo: productRepo,
+		storage:     storage,
+		cfg:         cfg,
+	}
+}
+
+func (s *productImageService) FindAll(ctx context.Context, productID uuid.UUID) ([]domain.ProductImage, error) {
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByProductID(ctx, productID)
+}
+
+func (s *productImageService) FindByID(ctx context.Context, productID, imageID uuid.UUID) (*domain.ProductImage, error) {
+	return s.repo.FindByID(ctx, productID, imageID)
+}
+
+func (s *productImageService) Upload(ctx context.Context, productID uuid.UUID, data []byte, altText string) (*domain.ProductImage, error) {
+	altText = strings.TrimSpace(altText)
+	if len(data) == 0 || len(altText) > maxAltTextLength {
+		return nil, domain.ErrBadParamInput
+	}
+	if len(data) > s.cfg.Storage.MaxImageSize {
+		return nil, domain.ErrFileTooLarge
+	}
+	// Trust the bytes, not the file name or the client's Content-Type
+	contentType := http.DetectContentType(data)
+	ext, ok := domain.ImageContentTypes[contentType]
+	if !ok {
+		return nil, domain.ErrUnsupportedFileType
+	}
+
+	if _, err := s.productRepo.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	key := "products/" + productID.String() + "/" + uuid.NewString() + ext
+	url, err := s.storage.Put(ctx, key, data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &domain.ProductImage{
+		ProductID:   productID,
+		URL:         url,
+		StorageKey:  key,
+		AltText:     altText,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	if err := s.repo.Create(ctx, image); err != nil {
+		s.removeFile(ctx, key)
+		return nil, err
+	}
+	return image, nil
+}
+
+func (s *productImageService) UpdateAltText(ctx context.Context, productID, imageID uuid.UUID, req domain.UpdateProductImageRequest) (*domain.ProductImage, error) {
+	image, err := s.repo.FindByID(ctx, productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	altText := strings.TrimSpace(req.AltText)
+	if len(altText) > maxAltTextLength {
+		return nil, domain.ErrBadParamInput
+	}
+
+	image.AltText = altText
+	if err := s.repo.Update(ctx, image); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+func (s *productImageService) Reorder(ctx context.Context, productID uuid.UUID, req domain.ReorderProductImagesRequest) ([]domain.ProductImage, error) {
+	images, err := s.FindAll(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A full permutation, so no two images end up on the same position
+	if len(req.ImageIDs) != len(images) {
+		return nil, domain.ErrInvalidImageOrder
+	}
+	current := make(map[uuid.UUID]bool, len(images))
+	for _, image := range images {
+		current[image.ID] = true
+	}
+	for _, id := range req.ImageIDs {
+		if !current[id] {
+			return nil, domain.ErrInvalidImageOrder
+		}
+		delete(current, id)
+	}
+
+	if err := s.repo.Reorder(ctx, productID, req.ImageIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByProductID(ctx, productID)
+}
+
+func (s *productImageService) Delete(ctx context.Context, productID, imageID uuid.UUID) error {
+	image, err := s.repo.FindByID(ctx, productID, imageID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, productID, imageID); err != nil {
+		return err
+	}
+	s.removeFile(ctx, image.StorageKey)
+	return nil
+}
+
+// removeFile deletes a stored file that is no longer referenced. The database is
+// already consistent at this point, so a failure only leaves an orphaned file behind.
+func (s *productImageService) removeFile(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Printf("Failed to delete stored file %s: %v", key, err)
+	}
+}
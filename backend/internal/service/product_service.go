@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"log"
 
 	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/domain"
@@ -11,28 +12,46 @@ import (
 type productService struct {
 	repo         domain.ProductRepository
 	categoryRepo domain.CategoryRepository
+	shopRepo     domain.ShopRepository
+	storage      domain.Storage
 }
 
 type ProductService interface {
-	Create(ctx context.Context, req domain.CreateProductRequest) error
+	Create(ctx context.Context, req domain.CreateProductRequest) (*domain.Product, error)
 	FindAll(ctx context.Context, params domain.ProductQueryParams) ([]domain.Product, int64, error)
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	// FindListedByID is the public lookup, products of shops that aren't approved are ErrNotFound
+	FindListedByID(ctx context.Context, id uuid.UUID) (*domain.Product, error)
 	FindBySlug(ctx context.Context, slug string) (*domain.Product, error)
 	Update(ctx context.Context, id uuid.UUID, req domain.CreateProductRequest) error
 	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Seller catalog, scoped to the products of the seller's approved shop.
+	// Products of other shops (or the store's own) are ErrNotFound.
+	CreateForSeller(ctx context.Context, ownerID uuid.UUID, req domain.CreateProductRequest) (*domain.Product, error)
+	FindAllForSeller(ctx context.Context, ownerID uuid.UUID, params domain.ProductQueryParams) ([]domain.Product, int64, error)
+	FindByIDForSeller(ctx context.Context, ownerID, id uuid.UUID) (*domain.Product, error)
+	UpdateForSeller(ctx context.Context, ownerID, id uuid.UUID, req domain.CreateProductRequest) error
+	DeleteForSeller(ctx context.Context, ownerID, id uuid.UUID) error
 }
 
-func NewProductService(repo domain.ProductRepository, categoryRepo domain.CategoryRepository) ProductService {
+func NewProductService(repo domain.ProductRepository, categoryRepo domain.CategoryRepository, shopRepo domain.ShopRepository, storage domain.Storage) ProductService {
 	return &productService{
 		repo:         repo,
 		categoryRepo: categoryRepo,
+		shopRepo:     shopRepo,
+		storage:      storage,
 	}
 }
 
-func (s *productService) Create(ctx context.Context, req domain.CreateProductRequest) error {
+func (s *productService) Create(ctx context.Context, req domain.CreateProductRequest) (*domain.Product, error) {
+	return s.create(ctx, nil, req)
+}
+
+func (s *productService) create(ctx context.Context, shopID *uuid.UUID, req domain.CreateProductRequest) (*domain.Product, error) {
 	// Validate Category
 	if _, err := s.categoryRepo.FindByID(ctx, req.CategoryID); err != nil {
-		return err // Could verify if specific error needed
+		return nil, err // Could verify if specific error needed
 	}
 
 	slug := utils.MakeSlug(req.Name)
@@ -46,8 +65,12 @@ func (s *productService) Create(ctx context.Context, req domain.CreateProductReq
 		Stock:       req.Stock,
 		CategoryID:  req.CategoryID,
 		ImageURL:    req.ImageURL,
+		ShopID:      shopID,
+	}
+	if err := s.repo.Create(ctx, product); err != nil {
+		return nil, err
 	}
-	return s.repo.Create(ctx, product)
+	return product, nil
 }
 
 func (s *productService) FindAll(ctx context.Context, params domain.ProductQueryParams) ([]domain.Product, int64, error) {
@@ -64,6 +87,10 @@ func (s *productService) FindByID(ctx context.Context, id uuid.UUID) (*domain.Pr
 	return s.repo.FindByID(ctx, id)
 }
 
+func (s *productService) FindListedByID(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	return s.repo.FindListedByID(ctx, id)
+}
+
 func (s *productService) FindBySlug(ctx context.Context, slug string) (*domain.Product, error) {
 	return s.repo.FindBySlug(ctx, slug)
 }
@@ -73,7 +100,10 @@ func (s *productService) Update(ctx context.Context, id uuid.UUID, req domain.Cr
 	if err != nil {
 		return err
 	}
+	return s.update(ctx, product, req)
+}
 
+func (s *productService) update(ctx context.Context, product *domain.Product, req domain.CreateProductRequest) error {
 	// Validate Category if changed
 	if req.CategoryID != uuid.Nil && req.CategoryID != product.CategoryID {
 		if _, err := s.categoryRepo.FindByID(ctx, req.CategoryID); err != nil {
@@ -92,7 +122,8 @@ func (s *productService) Update(ctx context.Context, id uuid.UUID, req domain.Cr
 	if req.Price > 0 {
 		product.Price = req.Price
 	}
-	if req.Stock >= 0 {
+	// A product with variants keeps the sum of their stock
+	if req.Stock >= 0 && len(product.Variants) == 0 {
 		product.Stock = req.Stock
 	}
 	if req.ImageURL != "" {
@@ -103,5 +134,101 @@ func (s *productService) Update(ctx context.Context, id uuid.UUID, req domain.Cr
 }
 
 func (s *productService) Delete(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeImageFiles(ctx, product.Images)
+	return nil
+}
+
+func (s *productService) CreateForSeller(ctx context.Context, ownerID uuid.UUID, req domain.CreateProductRequest) (*domain.Product, error) {
+	shop, err := s.sellerShop(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.create(ctx, &shop.ID, req)
+}
+
+func (s *productService) FindAllForSeller(ctx context.Context, ownerID uuid.UUID, params domain.ProductQueryParams) ([]domain.Product, int64, error) {
+	shop, err := s.sellerShop(ctx, ownerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	params.ShopID = shop.ID.String()
+	return s.FindAll(ctx, params)
+}
+
+func (s *productService) FindByIDForSeller(ctx context.Context, ownerID, id uuid.UUID) (*domain.Product, error) {
+	shop, err := s.sellerShop(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.findInShop(ctx, shop.ID, id)
+}
+
+func (s *productService) UpdateForSeller(ctx context.Context, ownerID, id uuid.UUID, req domain.CreateProductRequest) error {
+	shop, err := s.sellerShop(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	product, err := s.findInShop(ctx, shop.ID, id)
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, product, req)
+}
+
+func (s *productService) DeleteForSeller(ctx context.Context, ownerID, id uuid.UUID) error {
+	shop, err := s.sellerShop(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	product, err := s.findInShop(ctx, shop.ID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteInShop(ctx, shop.ID, id); err != nil {
+		return err
+	}
+	s.removeImageFiles(ctx, product.Images)
+	return nil
+}
+
+// removeImageFiles deletes the stored gallery files of a deleted product, their rows went with it
+func (s *productService) removeImageFiles(ctx context.Context, images []domain.ProductImage) {
+	for _, image := range images {
+		if err := s.storage.Delete(ctx, image.StorageKey); err != nil {
+			log.Printf("Failed to delete stored file %s: %v", image.StorageKey, err)
+		}
+	}
+}
+
+// sellerShop returns the user's shop, ErrNoShop without one and ErrShopNotApproved until an admin approved it
+func (s *productService) sellerShop(ctx context.Context, ownerID uuid.UUID) (*domain.Shop, error) {
+	shop, err := s.shopRepo.FindByOwnerID(ctx, ownerID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrNoShop
+		}
+		return nil, err
+	}
+	if !shop.IsApproved() {
+		return nil, domain.ErrShopNotApproved
+	}
+	return shop, nil
+}
+
+func (s *productService) findInShop(ctx context.Context, shopID, id uuid.UUID) (*domain.Product, error) {
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.ShopID == nil || *product.ShopID != shopID {
+		return nil, domain.ErrNotFound
+	}
+	return product, nil
 }
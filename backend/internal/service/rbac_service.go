@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+// permissionCacheTTL bounds how long a role's permissions and 2FA policy are served from memory
+// before being reloaded, so changes made directly in the database still propagate.
+const permissionCacheTTL = 5 * time.Minute
+
+type cachedRole struct {
+	permissions      map[string]struct{}
+	requireTwoFactor bool
+	expiresAt        time.Time
+}
+
+type rbacService struct {
+	roleRepo domain.RoleRepository
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cachedRole
+}
+
+func NewRBACService(roleRepo domain.RoleRepository) domain.RBACService {
+	return &rbacService{
+		roleRepo: roleRepo,
+		cache:    make(map[uuid.UUID]cachedRole),
+	}
+}
+
+func (s *rbacService) GetPermissions(ctx context.Context, roleID uuid.UUID) (map[string]struct{}, error) {
+	entry, err := s.getRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.permissions, nil
+}
+
+func (s *rbacService) RequiresTwoFactor(ctx context.Context, roleID uuid.UUID) (bool, error) {
+	entry, err := s.getRole(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+	return entry.requireTwoFactor, nil
+}
+
+func (s *rbacService) getRole(ctx context.Context, roleID uuid.UUID) (cachedRole, error) {
+	if roleID == uuid.Nil {
+		return cachedRole{permissions: map[string]struct{}{}}, nil
+	}
+
+	s.mu.RLock()
+	entry, ok := s.cache[roleID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry, nil
+	}
+
+	role, err := s.roleRepo.FindByID(ctx, roleID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			// Role was deleted, treat as no permissions
+			return cachedRole{permissions: map[string]struct{}{}}, nil
+		}
+		return cachedRole{}, err
+	}
+
+	permissions := make(map[string]struct{}, len(role.Permissions))
+	for _, p := range role.Permissions {
+		permissions[p.Name] = struct{}{}
+	}
+
+	entry = cachedRole{
+		permissions:      permissions,
+		requireTwoFactor: role.RequireTwoFactor,
+		expiresAt:        time.Now().Add(permissionCacheTTL),
+	}
+	s.mu.Lock()
+	s.cache[roleID] = entry
+	s.mu.Unlock()
+
+	return entry, nil
+}
+
+func (s *rbacService) HasPermission(ctx context.Context, roleID uuid.UUID, permission string) (bool, error) {
+	permissions, err := s.GetPermissions(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+	_, ok := permissions[permission]
+	return ok, nil
+}
+
+func (s *rbacService) InvalidateRole(roleID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, roleID)
+	s.mu.Unlock()
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+const (
+	revocationCacheSize = 10000
+	// Active sessions and users are re-checked against Postgres after this long, so a revocation
+	// or a disabled account on another instance takes effect within the window.
+	revocationRecheckInterval = 30 * time.Second
+	// LastSeenAt is written at most this often per session
+	lastSeenInterval = time.Minute
+)
+
+type revocationEntry struct {
+	revoked   bool
+	checkedAt time.Time
+	touchedAt time.Time
+}
+
+type disabledEntry struct {
+	disabled  bool
+	checkedAt time.Time
+}
+
+// revocationStore is the sessions and users tables with in-memory LRUs in front of them
+type revocationStore struct {
+	sessionRepo domain.SessionRepository
+	userRepo    domain.UserRepository
+	cache       *utils.LRU[uuid.UUID, revocationEntry]
+	users       *utils.LRU[uuid.UUID, disabledEntry]
+}
+
+func NewRevocationStore(sessionRepo domain.SessionRepository, userRepo domain.UserRepository) domain.RevocationStore {
+	return &revocationStore{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		cache:       utils.NewLRU[uuid.UUID, revocationEntry](revocationCacheSize),
+		users:       utils.NewLRU[uuid.UUID, disabledEntry](revocationCacheSize),
+	}
+}
+
+func (s *revocationStore) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	entry, ok := s.cache.Get(sessionID)
+	if ok && (entry.revoked || time.Since(entry.checkedAt) < revocationRecheckInterval) {
+		return entry.revoked, nil
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			// Unknown session, treat as revoked
+			s.cache.Add(sessionID, revocationEntry{revoked: true, checkedAt: time.Now()})
+			return true, nil
+		}
+		return false, err
+	}
+
+	revoked := session.RevokedAt != nil || time.Now().After(session.ExpiresAt)
+	entry.revoked = revoked
+	entry.checkedAt = time.Now()
+	if entry.touchedAt.IsZero() {
+		entry.touchedAt = session.LastSeenAt
+	}
+	s.cache.Add(sessionID, entry)
+	return revoked, nil
+}
+
+func (s *revocationStore) MarkRevoked(sessionIDs ...uuid.UUID) {
+	for _, id := range sessionIDs {
+		s.cache.Add(id, revocationEntry{revoked: true, checkedAt: time.Now()})
+	}
+}
+
+// IsUserDisabled also covers erased and deleted accounts. Unlike a revocation it can be undone,
+// so a disabled user is re-checked after the same interval as an active one.
+func (s *revocationStore) IsUserDisabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	if entry, ok := s.users.Get(userID); ok && time.Since(entry.checkedAt) < revocationRecheckInterval {
+		return entry.disabled, nil
+	}
+
+	disabled := true
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil && err != domain.ErrNotFound {
+		return false, err
+	}
+	if err == nil {
+		disabled = user.DisabledAt != nil || user.AnonymizedAt != nil
+	}
+	s.users.Add(userID, disabledEntry{disabled: disabled, checkedAt: time.Now()})
+	return disabled, nil
+}
+
+func (s *revocationStore) MarkUserDisabled(userID uuid.UUID, disabled bool) {
+	s.users.Add(userID, disabledEntry{disabled: disabled, checkedAt: time.Now()})
+}
+
+func (s *revocationStore) Touch(ctx context.Context, sessionID uuid.UUID, ipAddress string) {
+	entry, ok := s.cache.Get(sessionID)
+	if !ok || entry.revoked || time.Since(entry.touchedAt) < lastSeenInterval {
+		return
+	}
+
+	now := time.Now()
+	if err := s.sessionRepo.UpdateLastSeen(ctx, sessionID, ipAddress, now); err != nil {
+		log.Printf("Failed to update last seen for session %s: %v", sessionID, err)
+		return
+	}
+	entry.touchedAt = now
+	s.cache.Add(sessionID, entry)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type sessionService struct {
+	repo             domain.SessionRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	revocationStore  domain.RevocationStore
+}
+
+func NewSessionService(repo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository, revocationStore domain.RevocationStore) domain.SessionService {
+	return &sessionService{
+		repo:             repo,
+		refreshTokenRepo: refreshTokenRepo,
+		revocationStore:  revocationStore,
+	}
+}
+
+func (s *sessionService) CreateSession(ctx context.Context, userID uuid.UUID, client domain.ClientInfo, expiresAt time.Time, twoFactorVerified bool) (*domain.Session, error) {
+	session := &domain.Session{
+		ID:                uuid.New(),
+		UserID:            userID,
+		UserAgent:         client.UserAgent,
+		IPAddress:         client.IPAddress,
+		LastSeenAt:        time.Now(),
+		ExpiresAt:         expiresAt,
+		TwoFactorVerified: twoFactorVerified,
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *sessionService) GetSession(ctx context.Context, sessionID uuid.UUID) (*domain.Session, error) {
+	return s.repo.FindByID(ctx, sessionID)
+}
+
+func (s *sessionService) MarkTwoFactorVerified(ctx context.Context, sessionID uuid.UUID) error {
+	return s.repo.MarkTwoFactorVerified(ctx, sessionID)
+}
+
+func (s *sessionService) ExtendSession(ctx context.Context, sessionID uuid.UUID, expiresAt time.Time) error {
+	return s.repo.ExtendExpiry(ctx, sessionID, expiresAt)
+}
+
+func (s *sessionService) ListSessions(ctx context.Context, userID, currentSessionID uuid.UUID) ([]domain.Session, error) {
+	sessions, err := s.repo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.repo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Check ownership, don't reveal other users' sessions
+	if session.UserID != userID {
+		return domain.ErrNotFound
+	}
+
+	if err := s.repo.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+	s.revocationStore.MarkRevoked(sessionID)
+	return nil
+}
+
+func (s *sessionService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	ids, err := s.repo.RevokeAllByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, userID); err != nil {
+		return err
+	}
+	s.revocationStore.MarkRevoked(ids...)
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+type shopService struct {
+	repo domain.ShopRepository
+}
+
+func NewShopService(repo domain.ShopRepository) domain.ShopService {
+	return &shopService{repo: repo}
+}
+
+func (s *shopService) Apply(ctx context.Context, ownerID uuid.UUID, req domain.CreateShopRequest) (*domain.Shop, error) {
+	if existing, _ := s.repo.FindByOwnerID(ctx, ownerID); existing != nil {
+		return nil, domain.ErrConflict
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	slug := utils.MakeSlug(name)
+	if existing, _ := s.repo.FindBySlug(ctx, slug); existing != nil {
+		return nil, domain.ErrConflict
+	}
+
+	shop := &domain.Shop{
+		OwnerID:     ownerID,
+		Name:        name,
+		Slug:        slug,
+		Description: req.Description,
+		Status:      domain.ShopStatusPending,
+	}
+	if err := s.repo.Create(ctx, shop); err != nil {
+		return nil, err
+	}
+	return shop, nil
+}
+
+func (s *shopService) GetMyShop(ctx context.Context, ownerID uuid.UUID) (*domain.Shop, error) {
+	return s.repo.FindByOwnerID(ctx, ownerID)
+}
+
+func (s *shopService) UpdateMyShop(ctx context.Context, ownerID uuid.UUID, req domain.UpdateShopRequest) (*domain.Shop, error) {
+	shop, err := s.repo.FindByOwnerID(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	if name != shop.Name {
+		// Public shop pages are addressed by slug, it follows the name
+		slug := utils.MakeSlug(name)
+		if existing, _ := s.repo.FindBySlug(ctx, slug); existing != nil && existing.ID != shop.ID {
+			return nil, domain.ErrConflict
+		}
+		shop.Name = name
+		shop.Slug = slug
+	}
+	shop.Description = req.Description
+
+	if shop.Status == domain.ShopStatusRejected {
+		shop.Status = domain.ShopStatusPending
+		shop.RejectionReason = ""
+	}
+
+	if err := s.repo.Update(ctx, shop); err != nil {
+		return nil, err
+	}
+	return shop, nil
+}
+
+func (s *shopService) GetPublicShop(ctx context.Context, slug string) (*domain.Shop, error) {
+	shop, err := s.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !shop.IsApproved() {
+		return nil, domain.ErrNotFound
+	}
+	return shop, nil
+}
+
+func (s *shopService) ListShops(ctx context.Context, params domain.ShopQueryParams) ([]domain.Shop, int64, error) {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 {
+		params.Limit = 10
+	}
+	switch params.Status {
+	case "", domain.ShopStatusPending, domain.ShopStatusApproved, domain.ShopStatusRejected, domain.ShopStatusSuspended:
+	default:
+		return nil, 0, domain.ErrBadParamInput
+	}
+	return s.repo.FindAll(ctx, params)
+}
+
+func (s *shopService) GetShop(ctx context.Context, id uuid.UUID) (*domain.Shop, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *shopService) ApproveShop(ctx context.Context, reviewerID, shopID uuid.UUID) (*domain.Shop, error) {
+	// Approving a suspended shop reinstates it
+	return s.review(ctx, reviewerID, shopID, domain.ShopStatusApproved, "", domain.ErrShopNotPending, domain.ShopStatusPending, domain.ShopStatusSuspended)
+}
+
+func (s *shopService) RejectShop(ctx context.Context, reviewerID, shopID uuid.UUID, req domain.RejectShopRequest) (*domain.Shop, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	return s.review(ctx, reviewerID, shopID, domain.ShopStatusRejected, reason, domain.ErrShopNotPending, domain.ShopStatusPending)
+}
+
+func (s *shopService) SuspendShop(ctx context.Context, reviewerID, shopID uuid.UUID, req domain.RejectShopRequest) (*domain.Shop, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	return s.review(ctx, reviewerID, shopID, domain.ShopStatusSuspended, reason, domain.ErrShopNotSuspendable, domain.ShopStatusApproved)
+}
+
+// review moves the shop to status if it is in one of the from statuses, notAllowed otherwise
+func (s *shopService) review(ctx context.Context, reviewerID, shopID uuid.UUID, status, reason string, notAllowed error, from ...string) (*domain.Shop, error) {
+	shop, err := s.repo.FindByID(ctx, shopID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(from, shop.Status) {
+		return nil, notAllowed
+	}
+
+	now := time.Now()
+	shop.Status = status
+	shop.RejectionReason = reason
+	shop.ReviewedByID = &reviewerID
+	shop.ReviewedAt = &now
+	if err := s.repo.Update(ctx, shop); err != nil {
+		return nil, err
+	}
+	return shop, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// recoveryCodeCount is how many recovery codes are issued on enrollment
+const recoveryCodeCount = 10
+
+type twoFactorService struct {
+	userRepo         domain.UserRepository
+	recoveryCodeRepo domain.RecoveryCodeRepository
+	sessionService   domain.SessionService
+	config           *config.Config
+}
+
+func NewTwoFactorService(userRepo domain.UserRepository, recoveryCodeRepo domain.RecoveryCodeRepository, sessionService domain.SessionService, cfg *config.Config) domain.TwoFactorService {
+	return &twoFactorService{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		sessionService:   sessionService,
+		config:           cfg,
+	}
+}
+
+func (s *twoFactorService) Setup(ctx context.Context, userID uuid.UUID) (*domain.TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, domain.ErrConflict
+	}
+
+	// Starting over replaces any secret from an unfinished enrollment
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, domain.ErrInternalServerError
+	}
+	if err := s.userRepo.UpdateTwoFactor(ctx, userID, secret, false); err != nil {
+		return nil, err
+	}
+
+	return &domain.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURL: utils.TOTPURI(s.config.Server.AppName, user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Enable(ctx context.Context, userID, sessionID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, domain.ErrConflict
+	}
+	if user.TOTPSecret == "" {
+		return nil, domain.ErrBadParamInput // Setup wasn't called
+	}
+
+	// Only a TOTP code proves the authenticator app was set up correctly
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrInvalidCode
+	}
+
+	if err := s.userRepo.UpdateTwoFactor(ctx, userID, user.TOTPSecret, true); err != nil {
+		return nil, err
+	}
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The code was just entered on this device, so the current session counts as verified
+	if err := s.sessionService.MarkTwoFactorVerified(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID uuid.UUID, req domain.DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return nil
+	}
+
+	// Provider-only accounts have no password to confirm, the code alone has to do
+	if user.Password != "" && !utils.CheckPasswordHash(req.Password, user.Password) {
+		return domain.ErrWrongPassword
+	}
+	ok, err := s.VerifyCode(ctx, user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrInvalidCode
+	}
+
+	if err := s.userRepo.UpdateTwoFactor(ctx, userID, "", false); err != nil {
+		return err
+	}
+	return s.recoveryCodeRepo.DeleteAll(ctx, userID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, domain.ErrBadParamInput
+	}
+
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrInvalidCode
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+func (s *twoFactorService) VerifyCode(ctx context.Context, user *domain.User, code string) (bool, error) {
+	if !user.TwoFactorEnabled || user.TOTPSecret == "" {
+		return false, nil
+	}
+
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	// Fall back to a recovery code, each works once
+	normalized := utils.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+	return s.recoveryCodeRepo.Consume(ctx, user.ID, utils.HashToken(normalized))
+}
+
+// verifyTOTP checks the code and records its time step so it can't be replayed
+func (s *twoFactorService) verifyTOTP(ctx context.Context, user *domain.User, code string) (bool, error) {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return s.userRepo.UpdateTOTPLastStep(ctx, user.ID, step)
+}
+
+// replaceRecoveryCodes invalidates the old codes and returns a new set in plain text.
+// Only hashes are stored, so this is the only time the user sees them.
+func (s *twoFactorService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, domain.ErrInternalServerError
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceAll(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeRecoveryCodeRepository struct {
+	domain.RecoveryCodeRepository
+	codes map[string]bool // Hash to used
+}
+
+func (r *fakeRecoveryCodeRepository) Consume(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	used, ok := r.codes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[codeHash] = true
+	return true, nil
+}
+
+func (r *fakeRecoveryCodeRepository) DeleteAll(ctx context.Context, userID uuid.UUID) error {
+	clear(r.codes)
+	return nil
+}
+
+// newTestTwoFactorService enrolls a user with password "secret" and one recovery code, "abcde-fghjk"
+func newTestTwoFactorService(t *testing.T) (*twoFactorService, *domain.User) {
+	t.Helper()
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &domain.User{ID: uuid.New(), Password: string(hash), TwoFactorEnabled: true, TOTPSecret: secret}
+
+	return &twoFactorService{
+		userRepo: &fakeUserRepository{users: map[uuid.UUID]*domain.User{user.ID: user}},
+		recoveryCodeRepo: &fakeRecoveryCodeRepository{codes: map[string]bool{
+			utils.HashToken(utils.NormalizeRecoveryCode("abcde-fghjk")): false,
+		}},
+		config: testConfig(),
+	}, user
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := utils.GenerateTOTPCode(secret, utils.TOTPStep(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestTwoFactorServiceVerifyCode(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		// codes are entered in order, want holds the result for each
+		codes func(secret string) []string
+		want  []bool
+	}{
+		{
+			name:  "current code",
+			codes: func(secret string) []string { return []string{totpCode(t, secret, now)} },
+			want:  []bool{true},
+		},
+		{
+			name:  "replayed code",
+			codes: func(secret string) []string { return []string{totpCode(t, secret, now), totpCode(t, secret, now)} },
+			want:  []bool{true, false},
+		},
+		{
+			name: "older code after a newer one",
+			codes: func(secret string) []string {
+				return []string{totpCode(t, secret, now), totpCode(t, secret, now.Add(-utils.TOTPPeriod))}
+			},
+			want: []bool{true, false},
+		},
+		{
+			name:  "wrong code",
+			codes: func(secret string) []string { return []string{"000000x"} },
+			want:  []bool{false},
+		},
+		{
+			name:  "recovery code works once",
+			codes: func(secret string) []string { return []string{"abcde-fghjk", "abcde-fghjk"} },
+			want:  []bool{true, false},
+		},
+		{
+			name:  "recovery code is normalized",
+			codes: func(secret string) []string { return []string{" ABCDE FGHJK"} },
+			want:  []bool{true},
+		},
+		{
+			name:  "unknown recovery code",
+			codes: func(secret string) []string { return []string{"zzzzz-zzzzz"} },
+			want:  []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, user := newTestTwoFactorService(t)
+			for i, code := range tt.codes(user.TOTPSecret) {
+				ok, err := service.VerifyCode(context.Background(), user, code)
+				if err != nil {
+					t.Fatalf("VerifyCode: %v", err)
+				}
+				if ok != tt.want[i] {
+					t.Errorf("code %d accepted = %v, want %v", i+1, ok, tt.want[i])
+				}
+			}
+		})
+	}
+
+	t.Run("2FA disabled", func(t *testing.T) {
+		service, user := newTestTwoFactorService(t)
+		user.TwoFactorEnabled = false
+		if ok, _ := service.VerifyCode(context.Background(), user, totpCode(t, user.TOTPSecret, now)); ok {
+			t.Error("VerifyCode accepted a code while 2FA is disabled")
+		}
+	})
+}
+
+func TestTwoFactorServiceDisable(t *testing.T) {
+	tests := []struct {
+		name         string
+		passwordless bool // The account only signs in through a provider
+		password     string
+		code         func(secret string) string
+		wantErr      error
+		wantEnabled  bool
+	}{
+		{
+			name:     "password and TOTP code",
+			password: "secret",
+			code:     func(secret string) string { return totpCode(t, secret, time.Now()) },
+		},
+		{
+			name:     "password and recovery code",
+			password: "secret",
+			code:     func(secret string) string { return "abcde-fghjk" },
+		},
+		{
+			name:        "wrong password",
+			password:    "wrong",
+			code:        func(secret string) string { return totpCode(t, secret, time.Now()) },
+			wantErr:     domain.ErrWrongPassword,
+			wantEnabled: true,
+		},
+		{
+			name:        "wrong code",
+			password:    "secret",
+			code:        func(secret string) string { return "123456" },
+			wantErr:     domain.ErrInvalidCode,
+			wantEnabled: true,
+		},
+		{
+			name:         "provider-only account with a TOTP code",
+			passwordless: true,
+			code:         func(secret string) string { return totpCode(t, secret, time.Now()) },
+		},
+		{
+			name:         "provider-only account with a wrong code",
+			passwordless: true,
+			code:         func(secret string) string { return "123456" },
+			wantErr:      domain.ErrInvalidCode,
+			wantEnabled:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, user := newTestTwoFactorService(t)
+			if tt.passwordless {
+				user.Password = ""
+			}
+			req := domain.DisableTwoFactorRequest{Password: tt.password, Code: tt.code(user.TOTPSecret)}
+
+			if err := service.Disable(context.Background(), user.ID, req); err != tt.wantErr {
+				t.Fatalf("Disable error = %v, want %v", err, tt.wantErr)
+			}
+			if user.TwoFactorEnabled != tt.wantEnabled {
+				t.Errorf("2FA enabled = %v, want %v", user.TwoFactorEnabled, tt.wantEnabled)
+			}
+			codes := service.recoveryCodeRepo.(*fakeRecoveryCodeRepository).codes
+			if remaining := len(codes) > 0; remaining != tt.wantEnabled {
+				t.Errorf("recovery codes kept = %v, want %v", remaining, tt.wantEnabled)
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/user/go-ecommerce/internal/config"
@@ -10,63 +14,755 @@ import (
 )
 
 type userService struct {
-	userRepo domain.UserRepository
-	config   *config.Config
+	userRepo         domain.UserRepository
+	refreshTokenRepo domain.RefreshTokenRepository
+	userTokenRepo    domain.UserTokenRepository
+	identityRepo     domain.UserIdentityRepository
+	roleRepo         domain.RoleRepository
+	sessionService   domain.SessionService
+	loginThrottler   domain.LoginThrottler
+	passwordPolicy   domain.PasswordPolicy
+	twoFactorService domain.TwoFactorService
+	rbacService      domain.RBACService
+	mailer           domain.Mailer
+	config           *config.Config
 }
 
-func NewUserService(userRepo domain.UserRepository, cfg *config.Config) domain.UserService {
+func NewUserService(userRepo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, userTokenRepo domain.UserTokenRepository, identityRepo domain.UserIdentityRepository, roleRepo domain.RoleRepository, sessionService domain.SessionService, loginThrottler domain.LoginThrottler, passwordPolicy domain.PasswordPolicy, twoFactorService domain.TwoFactorService, rbacService domain.RBACService, mailer domain.Mailer, cfg *config.Config) domain.UserService {
 	return &userService{
-		userRepo: userRepo,
-		config:   cfg,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		userTokenRepo:    userTokenRepo,
+		identityRepo:     identityRepo,
+		roleRepo:         roleRepo,
+		sessionService:   sessionService,
+		loginThrottler:   loginThrottler,
+		passwordPolicy:   passwordPolicy,
+		twoFactorService: twoFactorService,
+		rbacService:      rbacService,
+		mailer:           mailer,
+		config:           cfg,
 	}
 }
 
-func (s *userService) Register(ctx context.Context, name, email, password string) error {
+func (s *userService) Register(ctx context.Context, name, email, password string) (*domain.User, error) {
 	// Check if user exists
 	existingUser, _ := s.userRepo.GetByEmail(ctx, email)
 	if existingUser != nil {
-		return domain.ErrConflict
+		return nil, domain.ErrConflict
+	}
+
+	if err := s.passwordPolicy.Validate(password, name, email); err != nil {
+		return nil, err
 	}
 
 	// Hash Password
-	hashedPassword, err := utils.HashPassword(password)
+	hashedPassword, err := utils.HashPassword(password, s.config)
 	if err != nil {
-		return domain.ErrInternalServerError
+		return nil, domain.ErrInternalServerError
+	}
+
+	roleID, err := s.defaultRoleID(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	user := &domain.User{
 		Name:     name,
 		Email:    email,
 		Password: hashedPassword,
-		// RoleID will be 0/Nil by default, should ideally set to a default "Customer" role
-        // For now, we will assume UUID.Nil or handle Role assignment separately/later
+		RoleID:   roleID,
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
 	}
 
-	return s.userRepo.Create(ctx, user)
+	// The account is usable right away, verification is only required by policy (e.g. checkout)
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+	return user, nil
 }
 
-func (s *userService) Login(ctx context.Context, email, password string) (string, error) {
+func (s *userService) Login(ctx context.Context, email, password string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	// Rejected before touching bcrypt so a locked account costs nothing to defend
+	if err := s.loginThrottler.Check(ctx, email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		if err == domain.ErrNotFound {
-			return "", domain.ErrUnauthorized // Don't reveal user existence
+			// Counted too, otherwise unknown emails could be probed without limit
+			if err := s.loginThrottler.RegisterFailure(ctx, email, client.IPAddress); err != nil {
+				return nil, err
+			}
+			return nil, domain.ErrUnauthorized // Don't reveal user existence
 		}
-		return "", err
+		s.releaseLoginAttempt(ctx, email, client.IPAddress)
+		return nil, err
 	}
 
 	if !utils.CheckPasswordHash(password, user.Password) {
-		return "", domain.ErrUnauthorized
+		if err := s.loginThrottler.RegisterFailure(ctx, email, client.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrUnauthorized
 	}
+	s.rehashPassword(ctx, user, password)
 
-	// Generate JWT
-	roleID := uuid.Nil
+	// With 2FA, failures aren't reset yet: the second factor is throttled by the same counters
+	if user.TwoFactorEnabled {
+		if err := s.loginThrottler.Release(ctx, email, client.IPAddress); err != nil {
+			return nil, err
+		}
+	} else if err := s.loginThrottler.RegisterSuccess(ctx, email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	return s.completeFirstFactor(ctx, user, client)
+}
+
+// releaseLoginAttempt gives back the attempt held by loginThrottler.Check when the login
+// ends in an error that is neither a failure nor a success
+func (s *userService) releaseLoginAttempt(ctx context.Context, email, ip string) {
+	if err := s.loginThrottler.Release(ctx, email, ip); err != nil {
+		log.Printf("Failed to release login attempt for %s: %v", email, err)
+	}
+}
+
+func (s *userService) LoginWithIdentity(ctx context.Context, identity domain.ExternalIdentity, client domain.ClientInfo) (*domain.LoginResult, error) {
+	user, claimed, err := s.findOrLinkIdentity(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.completeFirstFactor(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+	result.AccountClaimed = claimed
+	return result, nil
+}
+
+// findOrLinkIdentity resolves the local user for an external identity. Unknown identities are
+// linked to the account with the same email, or get a new account when there is none.
+// claimed reports that the account's email was unverified and its credentials were reset.
+func (s *userService) findOrLinkIdentity(ctx context.Context, identity domain.ExternalIdentity) (user *domain.User, claimed bool, err error) {
+	linked, err := s.identityRepo.FindByProviderSubject(ctx, identity.Provider, identity.Subject)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, false, err
+	}
+	if linked != nil {
+		if identity.Email != "" && identity.Email != linked.Email {
+			if err := s.identityRepo.UpdateEmail(ctx, linked.ID, identity.Email); err != nil {
+				return nil, false, err
+			}
+		}
+		user, err := s.userRepo.GetByID(ctx, linked.UserID)
+		return user, false, err
+	}
+
+	if identity.Email == "" {
+		return nil, false, domain.ErrEmailNotLinkable
+	}
+
+	user, err = s.userRepo.GetByEmail(ctx, identity.Email)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, false, err
+	}
+	if user != nil {
+		// Linking on an unverified email would let anyone who registers the address
+		// at the provider take over the local account
+		if !identity.EmailVerified {
+			return nil, false, domain.ErrEmailNotLinkable
+		}
+		// The other way round too: whoever registered the local account never proved
+		// they own the address, the provider just did
+		if user.VerifiedAt == nil {
+			if err := s.claimUnverifiedAccount(ctx, user); err != nil {
+				return nil, false, err
+			}
+			claimed = true
+		}
+	} else {
+		user, err = s.createExternalUser(ctx, identity)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := s.identityRepo.Create(ctx, &domain.UserIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}); err != nil {
+		return nil, false, err
+	}
+	return user, claimed, nil
+}
+
+// claimUnverifiedAccount hands an account with an unverified email to the owner of the address.
+// The password, 2FA and sessions were set up by someone who may not be them, so they are dropped.
+func (s *userService) claimUnverifiedAccount(ctx context.Context, user *domain.User) error {
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, ""); err != nil {
+		return err
+	}
+	if user.TwoFactorEnabled {
+		if err := s.userRepo.UpdateTwoFactor(ctx, user.ID, "", false); err != nil {
+			return err
+		}
+	}
+	if err := s.sessionService.RevokeAllSessions(ctx, user.ID); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := s.userRepo.MarkVerified(ctx, user.ID, now); err != nil {
+		return err
+	}
+
+	user.Password = ""
+	user.TwoFactorEnabled = false
+	user.TOTPSecret = ""
+	user.VerifiedAt = &now
+	return nil
+}
+
+// createExternalUser registers an account without a password, it can only sign in through
+// the provider until a password is set with the reset flow
+func (s *userService) createExternalUser(ctx context.Context, identity domain.ExternalIdentity) (*domain.User, error) {
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+
+	roleID, err := s.defaultRoleID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Name:   name,
+		Email:  identity.Email,
+		RoleID: roleID,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.VerifiedAt = &now
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if user.VerifiedAt == nil {
+		if err := s.sendVerificationEmail(ctx, user); err != nil {
+			log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+		}
+	}
+	return user, nil
+}
+
+// completeFirstFactor starts a session, or returns a 2FA challenge when the user has it enabled
+func (s *userService) completeFirstFactor(ctx context.Context, user *domain.User, client domain.ClientInfo) (*domain.LoginResult, error) {
+	if user.DisabledAt != nil {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	if user.TwoFactorEnabled {
+		challenge, err := utils.GenerateChallengeToken(user.ID, s.config)
+		if err != nil {
+			return nil, domain.ErrInternalServerError
+		}
+		return &domain.LoginResult{User: user, TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, client, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.LoginResult{User: user, Tokens: tokens}
 	if user.RoleID != nil {
-		roleID = *user.RoleID
+		// Tell the client to send the user through enrollment, guarded routes will refuse them until then
+		required, err := s.rbacService.RequiresTwoFactor(ctx, *user.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		result.TwoFactorSetupRequired = required
+	}
+	return result, nil
+}
+
+func (s *userService) CompleteTwoFactorLogin(ctx context.Context, req domain.VerifyTwoFactorRequest, client domain.ClientInfo) (*domain.LoginResult, error) {
+	userID, err := utils.ValidateChallengeToken(req.ChallengeToken, s.config)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if user.DisabledAt != nil {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	if err := s.loginThrottler.Check(ctx, user.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.twoFactorService.VerifyCode(ctx, user, req.Code)
+	if err != nil {
+		s.releaseLoginAttempt(ctx, user.Email, client.IPAddress)
+		return nil, err
+	}
+	if !ok {
+		if err := s.loginThrottler.RegisterFailure(ctx, user.Email, client.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidCode
+	}
+
+	if err := s.loginThrottler.RegisterSuccess(ctx, user.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.startSession(ctx, user, client, true)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+func (s *userService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, domain.ErrUnauthorized
+	}
+
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	// Reuse detection: a revoked token being presented again means it leaked,
+	// so the whole family (every token derived from the same login) is revoked.
+	if stored.RevokedAt != nil {
+		if err := s.revokeFamily(ctx, stored); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrUnauthorized
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, domain.ErrUnauthorized
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	if user.DisabledAt != nil {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	session, err := s.sessionService.GetSession(ctx, stored.FamilyID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	nextID := uuid.New()
+	rotated, err := s.refreshTokenRepo.Rotate(ctx, stored.ID, nextID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Lost the race against another refresh with the same token
+		if err := s.revokeFamily(ctx, stored); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrUnauthorized
+	}
+
+	tokens, err := s.issueTokens(ctx, user, session, nextID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionService.ExtendSession(ctx, stored.FamilyID, tokens.RefreshExpiresAt); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (s *userService) Logout(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	if refreshToken == "" {
+		return uuid.Nil, nil
+	}
+
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return uuid.Nil, nil // Already gone, nothing to revoke
+		}
+		return uuid.Nil, err
+	}
+
+	if err := s.revokeFamily(ctx, stored); err != nil {
+		return uuid.Nil, err
+	}
+	return stored.UserID, nil
+}
+
+func (s *userService) GetProfile(ctx context.Context, userID uuid.UUID) (*domain.UserProfile, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []string{}
+	if user.Role != nil {
+		for _, p := range user.Role.Permissions {
+			permissions = append(permissions, p.Name)
+		}
+	}
+
+	return &domain.UserProfile{User: user, Permissions: permissions}, nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, userID uuid.UUID, req domain.UpdateProfileRequest) (*domain.UserProfile, error) {
+	if req.Name != "" {
+		if err := s.userRepo.UpdateName(ctx, userID, req.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetProfile(ctx, userID)
+}
+
+func (s *userService) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, req domain.ChangePasswordRequest, client domain.ClientInfo) (*domain.TokenPair, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !utils.CheckPasswordHash(req.OldPassword, user.Password) {
+		return nil, domain.ErrWrongPassword
+	}
+	if err := s.passwordPolicy.Validate(req.NewPassword, user.Name, user.Email); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword, s.config)
+	if err != nil {
+		return nil, domain.ErrInternalServerError
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	// The replacement session keeps the 2FA state of the one making the change
+	twoFactorVerified := false
+	current, err := s.sessionService.GetSession(ctx, sessionID)
+	if err != nil && err != domain.ErrNotFound {
+		return nil, err
+	}
+	if current != nil && current.UserID == userID {
+		twoFactorVerified = current.TwoFactorVerified
+	}
+
+	// Anyone holding an old session (possibly the reason for the change) is logged out
+	if err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, client, twoFactorVerified)
+}
+
+// rehashPassword upgrades a hash made with an older algorithm or cost, the plain password
+// is only available right after it was checked. Failing to do so doesn't fail the login.
+func (s *userService) rehashPassword(ctx context.Context, user *domain.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password, s.config) {
+		return
+	}
+	hashedPassword, err := utils.HashPassword(password, s.config)
+	if err != nil {
+		log.Printf("Failed to rehash password of user %s: %v", user.ID, err)
+		return
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to rehash password of user %s: %v", user.ID, err)
+		return
+	}
+	user.Password = hashedPassword
+}
+
+// revokeFamily ends the session behind a refresh token, which also revokes its access tokens
+func (s *userService) revokeFamily(ctx context.Context, token *domain.RefreshToken) error {
+	err := s.sessionService.RevokeSession(ctx, token.UserID, token.FamilyID)
+	if err == domain.ErrNotFound {
+		// Token issued before sessions existed
+		return s.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID)
+	}
+	return err
+}
+
+func (s *userService) UnlockAccount(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.loginThrottler.Unlock(ctx, user.Email)
+}
+
+func (s *userService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil // Don't reveal user existence
+		}
+		return err
+	}
+
+	// Only the most recent link works
+	if err := s.userTokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	ttl := utils.ParseDurationOrDefault(s.config.Auth.PasswordResetExpiry, time.Hour)
+	token, err := s.createUserToken(ctx, user.ID, domain.TokenPurposePasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.config.Server.FrontendURL, url.QueryEscape(token))
+	msg := domain.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s. If you didn't request this, you can ignore this email.\n",
+			user.Name, link, ttl),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		// Don't fail the request, it would reveal that the account exists
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+	return nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, req domain.ResetPasswordRequest) error {
+	// Check the new password before using up the link, so a rejected one can be retried
+	pending, err := s.findUserToken(ctx, domain.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, pending.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.passwordPolicy.Validate(req.NewPassword, user.Name, user.Email); err != nil {
+		return err
+	}
+
+	token, err := s.consumeUserToken(ctx, domain.TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword, s.config)
+	if err != nil {
+		return domain.ErrInternalServerError
 	}
-	token, err := utils.GenerateToken(user.ID, roleID, s.config)
+	if err := s.userRepo.UpdatePassword(ctx, token.UserID, hashedPassword); err != nil {
+		return err
+	}
+
+	return s.sessionService.RevokeAllSessions(ctx, token.UserID)
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, token string) error {
+	userToken, err := s.consumeUserToken(ctx, domain.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.MarkVerified(ctx, userToken.UserID, time.Now())
+}
+
+func (s *userService) ResendVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.VerifiedAt != nil {
+		return domain.ErrAlreadyVerified
+	}
+
+	// Throttle so the endpoint can't be used to flood an inbox
+	interval := utils.ParseDurationOrDefault(s.config.Auth.VerificationResendInterval, time.Minute)
+	latest, err := s.userTokenRepo.FindLatest(ctx, userID, domain.TokenPurposeEmailVerification)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < interval {
+		return domain.ErrTooManyRequests
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+func (s *userService) sendVerificationEmail(ctx context.Context, user *domain.User) error {
+	// Only the most recent link works
+	if err := s.userTokenRepo.InvalidateAll(ctx, user.ID, domain.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	ttl := utils.ParseDurationOrDefault(s.config.Auth.EmailVerificationExpiry, 24*time.Hour)
+	token, err := s.createUserToken(ctx, user.ID, domain.TokenPurposeEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.config.Server.FrontendURL, url.QueryEscape(token))
+	return s.mailer.Send(ctx, domain.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nThanks for signing up! Please confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, link, ttl),
+	})
+}
+
+// createUserToken stores the hash of a new single-use token and returns the plain token
+func (s *userService) createUserToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	plain, err := utils.GenerateOpaqueToken()
 	if err != nil {
 		return "", domain.ErrInternalServerError
 	}
 
+	token := &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.userTokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// consumeUserToken validates a single-use token and marks it used.
+// Unknown, expired and already used tokens all return ErrInvalidToken.
+func (s *userService) consumeUserToken(ctx context.Context, purpose, plain string) (*domain.UserToken, error) {
+	token, err := s.findUserToken(ctx, purpose, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.userTokenRepo.MarkUsed(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, domain.ErrInvalidToken
+	}
 	return token, nil
 }
+
+// findUserToken returns a token that is still usable without consuming it
+func (s *userService) findUserToken(ctx context.Context, purpose, plain string) (*domain.UserToken, error) {
+	if plain == "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	token, err := s.userTokenRepo.FindByHash(ctx, purpose, utils.HashToken(plain))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+	return token, nil
+}
+
+// startSession creates a new session, which is also a new refresh token family
+func (s *userService) startSession(ctx context.Context, user *domain.User, client domain.ClientInfo, twoFactorVerified bool) (*domain.TokenPair, error) {
+	session, err := s.sessionService.CreateSession(ctx, user.ID, client, time.Now().Add(utils.RefreshTokenTTL(s.config)), twoFactorVerified)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user, session, uuid.New())
+}
+
+// issueTokens signs an access token for the session and stores a new refresh token in its family
+func (s *userService) issueTokens(ctx context.Context, user *domain.User, session *domain.Session, refreshTokenID uuid.UUID) (*domain.TokenPair, error) {
+	// Accounts created before roles were assigned on registration get the default role on their next sign in
+	if user.RoleID == nil {
+		roleID, err := s.defaultRoleID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.userRepo.UpdateRole(ctx, user.ID, roleID); err != nil {
+			return nil, err
+		}
+		user.RoleID = roleID
+	}
+
+	// Generate JWT
+	roleID := *user.RoleID
+	now := time.Now()
+	accessToken, err := utils.GenerateToken(user.ID, roleID, session.ID, session.TwoFactorVerified, s.config)
+	if err != nil {
+		return nil, domain.ErrInternalServerError
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, domain.ErrInternalServerError
+	}
+
+	stored := &domain.RefreshToken{
+		ID:        refreshTokenID,
+		UserID:    user.ID,
+		FamilyID:  session.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: now.Add(utils.RefreshTokenTTL(s.config)),
+	}
+	if err := s.refreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  now.Add(utils.AccessTokenTTL(s.config)),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// defaultRoleID resolves the role new accounts get, configured by name with DEFAULT_ROLE
+func (s *userService) defaultRoleID(ctx context.Context) (*uuid.UUID, error) {
+	role, err := s.roleRepo.FindByName(ctx, s.config.Auth.DefaultRole)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			log.Printf("Default role %q does not exist, run cmd/seed or fix DEFAULT_ROLE", s.config.Auth.DefaultRole)
+			return nil, domain.ErrInternalServerError
+		}
+		return nil, err
+	}
+	return &role.ID, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
+	"github.com/user/go-ecommerce/internal/domain"
+	"github.com/user/go-ecommerce/pkg/utils"
+)
+
+// Only the methods the services under test call are implemented, the embedded interfaces panic otherwise
+type fakeUserRepository struct {
+	domain.UserRepository
+	users map[uuid.UUID]*domain.User
+}
+
+func (r *fakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepository) UpdateTwoFactor(ctx context.Context, id uuid.UUID, secret string, enabled bool) error {
+	r.users[id].TOTPSecret = secret
+	r.users[id].TwoFactorEnabled = enabled
+	return nil
+}
+
+// UpdateTOTPLastStep only moves forward, like the SQL update it stands in for
+func (r *fakeUserRepository) UpdateTOTPLastStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	user := r.users[id]
+	if step <= user.TOTPLastStep {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+type fakeRefreshTokenRepository struct {
+	domain.RefreshTokenRepository
+	tokens map[string]*domain.RefreshToken // By hash
+	// loseRace makes Rotate fail as if another refresh rotated the token first
+	loseRace bool
+}
+
+func (r *fakeRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	token, ok := r.tokens[hash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepository) Rotate(ctx context.Context, id, replacedByID uuid.UUID) (bool, error) {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.RevokedAt != nil || r.loseRace {
+				return false, nil
+			}
+			now := time.Now()
+			token.RevokedAt = &now
+			token.ReplacedByID = &replacedByID
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// familyRevoked reports whether every token of the family is revoked
+func (r *fakeRefreshTokenRepository) familyRevoked(familyID uuid.UUID) bool {
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// fakeSessionService keeps sessions in memory, revoking one revokes its refresh token family
+type fakeSessionService struct {
+	domain.SessionService
+	sessions      map[uuid.UUID]*domain.Session
+	refreshTokens *fakeRefreshTokenRepository
+}
+
+func (s *fakeSessionService) GetSession(ctx context.Context, sessionID uuid.UUID) (*domain.Session, error) {
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return session, nil
+}
+
+func (s *fakeSessionService) ExtendSession(ctx context.Context, sessionID uuid.UUID, expiresAt time.Time) error {
+	if session, ok := s.sessions[sessionID]; ok {
+		session.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (s *fakeSessionService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, ok := s.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return domain.ErrNotFound
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return s.refreshTokens.RevokeFamily(ctx, sessionID)
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{AppName: "test"},
+		JWT:    config.JWTConfig{Secret: "test-secret"},
+	}
+}
+
+func TestUserServiceRefresh(t *testing.T) {
+	roleID := uuid.New()
+	user := &domain.User{ID: uuid.New(), Email: "user@example.com", RoleID: &roleID}
+	familyID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		// The family has a token rotated earlier ("used") and the live one ("current")
+		present           string
+		currentExpiresAt  time.Time
+		userID            uuid.UUID
+		loseRace          bool
+		noSession         bool // Token issued before sessions existed
+		wantErr           error
+		wantFamilyRevoked bool
+	}{
+		{name: "rotates the current token", present: "current"},
+		{name: "empty token", present: "", wantErr: domain.ErrUnauthorized},
+		{name: "unknown token", present: "unknown", wantErr: domain.ErrUnauthorized},
+		{name: "reused token revokes the family", present: "used", wantErr: domain.ErrUnauthorized, wantFamilyRevoked: true},
+		{name: "reused token without a session revokes the family", present: "used", noSession: true, wantErr: domain.ErrUnauthorized, wantFamilyRevoked: true},
+		{name: "ended session", present: "current", noSession: true, wantErr: domain.ErrUnauthorized},
+		{name: "expired token", present: "current", currentExpiresAt: past, wantErr: domain.ErrUnauthorized},
+		{name: "deleted user", present: "current", userID: uuid.New(), wantErr: domain.ErrUnauthorized},
+		{name: "concurrent rotation revokes the family", present: "current", loseRace: true, wantErr: domain.ErrUnauthorized, wantFamilyRevoked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ownerID := user.ID
+			if tt.userID != uuid.Nil {
+				ownerID = tt.userID
+			}
+			expiresAt := time.Now().Add(time.Hour)
+			if !tt.currentExpiresAt.IsZero() {
+				expiresAt = tt.currentExpiresAt
+			}
+			currentID := uuid.New()
+			repo := &fakeRefreshTokenRepository{
+				tokens: map[string]*domain.RefreshToken{
+					utils.HashToken("used"): {
+						ID: uuid.New(), UserID: ownerID, FamilyID: familyID, TokenHash: utils.HashToken("used"),
+						ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &past, ReplacedByID: &currentID,
+					},
+					utils.HashToken("current"): {
+						ID: currentID, UserID: ownerID, FamilyID: familyID, TokenHash: utils.HashToken("current"),
+						ExpiresAt: expiresAt,
+					},
+				},
+				loseRace: tt.loseRace,
+			}
+			sessions := &fakeSessionService{sessions: map[uuid.UUID]*domain.Session{}, refreshTokens: repo}
+			if !tt.noSession {
+				sessions.sessions[familyID] = &domain.Session{ID: familyID, UserID: ownerID, ExpiresAt: expiresAt, TwoFactorVerified: true}
+			}
+			service := &userService{
+				userRepo:         &fakeUserRepository{users: map[uuid.UUID]*domain.User{user.ID: user}},
+				refreshTokenRepo: repo,
+				sessionService:   sessions,
+				config:           testConfig(),
+			}
+
+			tokens, err := service.Refresh(context.Background(), tt.present)
+			if err != tt.wantErr {
+				t.Fatalf("Refresh error = %v, want %v", err, tt.wantErr)
+			}
+			if got := repo.familyRevoked(familyID); got != tt.wantFamilyRevoked {
+				t.Errorf("family revoked = %v, want %v", got, tt.wantFamilyRevoked)
+			}
+			if err != nil {
+				return
+			}
+
+			current := repo.tokens[utils.HashToken("current")]
+			next, ok := repo.tokens[utils.HashToken(tokens.RefreshToken)]
+			if !ok {
+				t.Fatal("the new refresh token wasn't stored")
+			}
+			if current.RevokedAt == nil || current.ReplacedByID == nil || *current.ReplacedByID != next.ID {
+				t.Error("the presented token wasn't marked as replaced by the new one")
+			}
+			if next.FamilyID != familyID || next.RevokedAt != nil {
+				t.Errorf("new token family = %v revoked = %v, want live token in %v", next.FamilyID, next.RevokedAt, familyID)
+			}
+			claims, err := utils.ValidateToken(tokens.AccessToken, service.config)
+			if err != nil {
+				t.Fatalf("access token invalid: %v", err)
+			}
+			if claims.SessionID != familyID || !claims.MFA {
+				t.Errorf("access token session = %v mfa = %v, want %v with mfa", claims.SessionID, claims.MFA, familyID)
+			}
+			if session := sessions.sessions[familyID]; !session.ExpiresAt.Equal(tokens.RefreshExpiresAt) {
+				t.Errorf("session expires at %v, want it extended to %v", session.ExpiresAt, tokens.RefreshExpiresAt)
+			}
+
+			// The rotated token is now a reused one
+			if _, err := service.Refresh(context.Background(), "current"); err != domain.ErrUnauthorized {
+				t.Errorf("second Refresh error = %v, want %v", err, domain.ErrUnauthorized)
+			}
+			if !repo.familyRevoked(familyID) || sessions.sessions[familyID].RevokedAt == nil {
+				t.Error("reusing the rotated token didn't revoke the session")
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/domain"
+)
+
+type variantService struct {
+	repo        domain.VariantRepository
+	productRepo domain.ProductRepository
+}
+
+// VariantService manages a product's option types and variants. Sellers go through
+// SellerHandler, which checks the product is in their shop first.
+type VariantService interface {
+	AddOption(ctx context.Context, productID uuid.UUID, req domain.CreateProductOptionRequest) (*domain.ProductOption, error)
+	DeleteOption(ctx context.Context, productID, optionID uuid.UUID) (*domain.ProductOption, error)
+	FindVariants(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error)
+	FindVariant(ctx context.Context, productID, variantID uuid.UUID) (*domain.ProductVariant, error)
+	CreateVariant(ctx context.Context, productID uuid.UUID, req domain.CreateVariantRequest) (*domain.ProductVariant, error)
+	UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, req domain.UpdateVariantRequest) (*domain.ProductVariant, error)
+	DeleteVariant(ctx context.Context, productID, variantID uuid.UUID) error
+}
+
+func NewVariantService(repo domain.VariantRepository, productRepo domain.ProductRepository) VariantService {
+	return &variantService{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+func (s *variantService) AddOption(ctx context.Context, productID uuid.UUID, req domain.CreateProductOptionRequest) (*domain.ProductOption, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	// Existing variants would have no value for the new option
+	if len(product.Variants) > 0 {
+		return nil, domain.ErrProductHasVariants
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	for _, option := range product.Options {
+		if strings.EqualFold(option.Name, name) {
+			return nil, domain.ErrConflict
+		}
+	}
+
+	option := &domain.ProductOption{
+		ProductID: productID,
+		Name:      name,
+		Position:  len(product.Options),
+	}
+	if err := s.repo.CreateOption(ctx, option); err != nil {
+		return nil, err
+	}
+	return option, nil
+}
+
+func (s *variantService) DeleteOption(ctx context.Context, productID, optionID uuid.UUID) (*domain.ProductOption, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(product.Variants) > 0 {
+		return nil, domain.ErrProductHasVariants
+	}
+
+	for _, option := range product.Options {
+		if option.ID == optionID {
+			if err := s.repo.DeleteOption(ctx, productID, optionID); err != nil {
+				return nil, err
+			}
+			return &option, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (s *variantService) FindVariants(ctx context.Context, productID uuid.UUID) ([]domain.ProductVariant, error) {
+	if _, err := s.productRepo.FindListedByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByProductID(ctx, productID)
+}
+
+func (s *variantService) FindVariant(ctx context.Context, productID, variantID uuid.UUID) (*domain.ProductVariant, error) {
+	return s.repo.FindByID(ctx, productID, variantID)
+}
+
+func (s *variantService) CreateVariant(ctx context.Context, productID uuid.UUID, req domain.CreateVariantRequest) (*domain.ProductVariant, error) {
+	product, err := s.productRepo.FindByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exactly one value for each of the product's options, nothing else
+	if len(product.Options) == 0 || len(req.Options) != len(product.Options) {
+		return nil, domain.ErrInvalidVariant
+	}
+	values := make(map[uuid.UUID]string, len(product.Options))
+	optionValues := make([]domain.VariantOptionValue, 0, len(product.Options))
+	for _, option := range product.Options {
+		value := strings.TrimSpace(req.Options[option.Name])
+		if value == "" {
+			return nil, domain.ErrInvalidVariant
+		}
+		values[option.ID] = value
+		optionValues = append(optionValues, domain.VariantOptionValue{OptionID: option.ID, Value: value})
+	}
+
+	// One variant per combination
+	for _, existing := range product.Variants {
+		if sameOptionValues(existing.Options, values) {
+			return nil, domain.ErrConflict
+		}
+	}
+
+	sku := strings.TrimSpace(req.SKU)
+	if sku == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	if existing, _ := s.repo.FindBySKU(ctx, sku); existing != nil {
+		return nil, domain.ErrConflict
+	}
+
+	variant := &domain.ProductVariant{
+		ProductID: productID,
+		SKU:       sku,
+		Title:     domain.VariantTitle(product.Options, values),
+		Price:     req.Price,
+		Stock:     req.Stock,
+		ImageURL:  req.ImageURL,
+		Options:   optionValues,
+	}
+	if err := s.repo.Create(ctx, variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *variantService) UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, req domain.UpdateVariantRequest) (*domain.ProductVariant, error) {
+	variant, err := s.repo.FindByID(ctx, productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	sku := strings.TrimSpace(req.SKU)
+	if sku == "" {
+		return nil, domain.ErrBadParamInput
+	}
+	if sku != variant.SKU {
+		if existing, _ := s.repo.FindBySKU(ctx, sku); existing != nil {
+			return nil, domain.ErrConflict
+		}
+	}
+
+	variant.SKU = sku
+	variant.Price = req.Price
+	variant.Stock = req.Stock
+	variant.ImageURL = req.ImageURL
+	if err := s.repo.Update(ctx, variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *variantService) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID) error {
+	return s.repo.Delete(ctx, productID, variantID)
+}
+
+func sameOptionValues(existing []domain.VariantOptionValue, values map[uuid.UUID]string) bool {
+	if len(existing) != len(values) {
+		return false
+	}
+	for _, value := range existing {
+		if !strings.EqualFold(values[value.OptionID], value.Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,12 @@
+package utils
+
+import "time"
+
+// ParseDurationOrDefault parses a duration from config, returning fallback when it's empty or invalid
+func ParseDurationOrDefault(value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,48 +10,244 @@ import (
 	"github.com/user/go-ecommerce/internal/config"
 )
 
+// challengeAudience marks the short-lived token issued between the password and TOTP steps
+const challengeAudience = "two_factor"
+
+// ChallengeTokenTTL is how long the user has to enter their TOTP code after the password step
+const ChallengeTokenTTL = 5 * time.Minute
+
+// oidcStateAudience marks the token carrying OIDC login state between the redirect and the callback
+const oidcStateAudience = "oidc_state"
+
+// OIDCStateTTL is how long the user has to complete the login at the provider
+const OIDCStateTTL = 10 * time.Minute
+
+// guestCartAudience marks the token identifying an anonymous visitor's cart
+const guestCartAudience = "guest_cart"
+
 type JWTClaims struct {
-	UserID uuid.UUID `json:"sub"`
-	RoleID uuid.UUID `json:"role"`
+	UserID    uuid.UUID `json:"sub"`
+	RoleID    uuid.UUID `json:"role"`
+	SessionID uuid.UUID `json:"sid"`
+	MFA       bool      `json:"mfa,omitempty"` // Session was started with a second factor
+	// Actor is the support user acting as UserID, only set on impersonation tokens
+	Actor *ActorClaim `json:"act,omitempty"`
+	// Set by AuthMiddleware for X-API-Key requests, never part of a token
+	APIKeyID uuid.UUID `json:"-"`
+	Scopes   []string  `json:"-"`
+	// ID holds the per-token "jti"
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID, roleID uuid.UUID, cfg *config.Config) (string, error) {
-	// Parse expiry duration from config or default to 24h
-	expiry, err := time.ParseDuration(cfg.JWT.Expiry)
-	if err != nil {
-		expiry = 24 * time.Hour
-	}
+// ActorClaim is the RFC 8693 "act" claim
+type ActorClaim struct {
+	UserID uuid.UUID `json:"sub"`
+}
+
+// Impersonating reports whether the token was issued to a support user acting as UserID
+func (c *JWTClaims) Impersonating() bool {
+	return c.Actor != nil
+}
+
+// AccessTokenTTL parses JWT_EXPIRY, defaulting to 15 minutes
+func AccessTokenTTL(cfg *config.Config) time.Duration {
+	return ParseDurationOrDefault(cfg.JWT.Expiry, 15*time.Minute)
+}
+
+// GuestCartTTL parses GUEST_CART_EXPIRY, defaulting to 30 days
+func GuestCartTTL(cfg *config.Config) time.Duration {
+	return ParseDurationOrDefault(cfg.Cart.GuestExpiry, 30*24*time.Hour)
+}
+
+// ImpersonationTokenTTL parses IMPERSONATION_EXPIRY, defaulting to 15 minutes
+func ImpersonationTokenTTL(cfg *config.Config) time.Duration {
+	return ParseDurationOrDefault(cfg.Auth.ImpersonationExpiry, 15*time.Minute)
+}
+
+// RefreshTokenTTL parses JWT_REFRESH_EXPIRY, defaulting to 30 days
+func RefreshTokenTTL(cfg *config.Config) time.Duration {
+	return ParseDurationOrDefault(cfg.JWT.RefreshExpiry, 30*24*time.Hour)
+}
+
+func GenerateToken(userID, roleID, sessionID uuid.UUID, mfa bool, cfg *config.Config) (string, error) {
+	expiry := AccessTokenTTL(cfg)
 
 	claims := JWTClaims{
-		UserID: userID,
-		RoleID: roleID,
+		UserID:    userID,
+		RoleID:    roleID,
+		SessionID: sessionID,
+		MFA:       mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			Issuer:    cfg.Server.AppName,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.JWT.Secret))
+	return signClaims(claims, cfg)
+}
+
+// GenerateImpersonationToken issues an access token for userID carrying actorID in the "act" claim.
+// It belongs to the actor's session, so signing out (or revoking that session) ends the impersonation.
+func GenerateImpersonationToken(userID, roleID, actorID, actorSessionID uuid.UUID, expiresAt time.Time, cfg *config.Config) (string, error) {
+	claims := JWTClaims{
+		UserID:    userID,
+		RoleID:    roleID,
+		SessionID: actorSessionID,
+		Actor:     &ActorClaim{UserID: actorID},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			Issuer:    cfg.Server.AppName,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signClaims(claims, cfg)
 }
 
 func ValidateToken(tokenString string, cfg *config.Config) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(cfg.JWT.Secret), nil
-	})
+	claims, err := parseToken(tokenString, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Access tokens have no audience, this refuses 2FA challenges, OIDC state and guest cart tokens
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
 
+// GenerateChallengeToken issues the token returned by the password step of a 2FA login
+func GenerateChallengeToken(userID uuid.UUID, cfg *config.Config) (string, error) {
+	claims := JWTClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ChallengeTokenTTL)),
+			Issuer:    cfg.Server.AppName,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signClaims(claims, cfg)
+}
+
+// ValidateChallengeToken returns the user ID a 2FA challenge was issued for
+func ValidateChallengeToken(tokenString string, cfg *config.Config) (uuid.UUID, error) {
+	claims, err := parseToken(tokenString, cfg)
 	if err != nil {
+		return uuid.Nil, err
+	}
+	if !slices.Contains(claims.Audience, challengeAudience) {
+		return uuid.Nil, errors.New("invalid token")
+	}
+	return claims.UserID, nil
+}
+
+// OIDCStateClaims is kept in a signed cookie while the user is at the provider
+type OIDCStateClaims struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
+func GenerateOIDCStateToken(state OIDCStateClaims, cfg *config.Config) (string, error) {
+	state.RegisteredClaims = jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{oidcStateAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(OIDCStateTTL)),
+		Issuer:    cfg.Server.AppName,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	return signClaims(state, cfg)
+}
+
+func ValidateOIDCStateToken(tokenString string, cfg *config.Config) (*OIDCStateClaims, error) {
+	claims := &OIDCStateClaims{}
+	if err := parseClaims(tokenString, claims, cfg, jwt.WithAudience(oidcStateAudience)); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateGuestCartToken signs the ID of a guest cart, kept in a cookie so visitors can't pick another cart
+func GenerateGuestCartToken(cartID uuid.UUID, cfg *config.Config) (string, error) {
+	claims := jwt.RegisteredClaims{
+		Subject:   cartID.String(),
+		Audience:  jwt.ClaimStrings{guestCartAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(GuestCartTTL(cfg))),
+		Issuer:    cfg.Server.AppName,
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+
+	return signClaims(claims, cfg)
+}
+
+// ValidateGuestCartToken returns the guest cart ID from a token made by GenerateGuestCartToken
+func ValidateGuestCartToken(tokenString string, cfg *config.Config) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+	if err := parseClaims(tokenString, claims, cfg, jwt.WithAudience(guestCartAudience)); err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(claims.Subject)
+}
+
+func parseToken(tokenString string, cfg *config.Config) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	if err := parseClaims(tokenString, claims, cfg); err != nil {
 		return nil, err
 	}
+	return claims, nil
+}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+// signClaims signs with the key ring's current key (kid in the header), or HS256 without a ring
+func signClaims(claims jwt.Claims, cfg *config.Config) (string, error) {
+	if keyRing == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(cfg.JWT.Secret))
 	}
 
-	return nil, errors.New("invalid token")
+	key, err := keyRing.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+func parseClaims(tokenString string, claims jwt.Claims, cfg *config.Config, opts ...jwt.ParserOption) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if keyRing == nil {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			return []byte(cfg.JWT.Secret), nil
+		}
+
+		// Once a key ring is configured HS256 is refused, the shared secret may be known elsewhere
+		kid, _ := token.Header["kid"].(string)
+		key, public, ok := keyRing.VerificationKey(kid, time.Now())
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return public, nil
+	}, opts...)
+
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
 }
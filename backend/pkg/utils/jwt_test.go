@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/user/go-ecommerce/internal/config"
+)
+
+func jwtConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{AppName: "test"},
+		JWT:    config.JWTConfig{Secret: "test-secret"},
+	}
+}
+
+// useTestKeyRing signs with a fresh Ed25519 key ring for the rest of the test
+func useTestKeyRing(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "test.pem")
+	ring, err := LoadKeyRing(dir)
+	if err != nil {
+		t.Fatalf("LoadKeyRing: %v", err)
+	}
+	UseKeyRing(ring)
+	t.Cleanup(func() { UseKeyRing(nil) })
+}
+
+// tokenKinds issues one token of each kind, validators must only accept their own
+var tokenKinds = []struct {
+	name     string
+	generate func(cfg *config.Config) (string, error)
+}{
+	{"access", func(cfg *config.Config) (string, error) {
+		return GenerateToken(uuid.New(), uuid.New(), uuid.New(), false, cfg)
+	}},
+	{"impersonation", func(cfg *config.Config) (string, error) {
+		return GenerateImpersonationToken(uuid.New(), uuid.New(), uuid.New(), uuid.New(), time.Now().Add(time.Minute), cfg)
+	}},
+	{"challenge", func(cfg *config.Config) (string, error) {
+		return GenerateChallengeToken(uuid.New(), cfg)
+	}},
+	{"oidc state", func(cfg *config.Config) (string, error) {
+		return GenerateOIDCStateToken(OIDCStateClaims{Provider: "google", State: "s"}, cfg)
+	}},
+	{"guest cart", func(cfg *config.Config) (string, error) {
+		return GenerateGuestCartToken(uuid.New(), cfg)
+	}},
+}
+
+func TestTokenAudiences(t *testing.T) {
+	validators := []struct {
+		name     string
+		validate func(token string, cfg *config.Config) error
+		accepts  map[string]bool
+	}{
+		{
+			name: "ValidateToken",
+			validate: func(token string, cfg *config.Config) error {
+				_, err := ValidateToken(token, cfg)
+				return err
+			},
+			accepts: map[string]bool{"access": true, "impersonation": true},
+		},
+		{
+			name: "ValidateChallengeToken",
+			validate: func(token string, cfg *config.Config) error {
+				_, err := ValidateChallengeToken(token, cfg)
+				return err
+			},
+			accepts: map[string]bool{"challenge": true},
+		},
+		{
+			name: "ValidateOIDCStateToken",
+			validate: func(token string, cfg *config.Config) error {
+				_, err := ValidateOIDCStateToken(token, cfg)
+				return err
+			},
+			accepts: map[string]bool{"oidc state": true},
+		},
+		{
+			name: "ValidateGuestCartToken",
+			validate: func(token string, cfg *config.Config) error {
+				_, err := ValidateGuestCartToken(token, cfg)
+				return err
+			},
+			accepts: map[string]bool{"guest cart": true},
+		},
+	}
+
+	for _, signing := range []string{"HS256", "key ring"} {
+		t.Run(signing, func(t *testing.T) {
+			if signing == "key ring" {
+				useTestKeyRing(t)
+			}
+			cfg := jwtConfig()
+
+			for _, kind := range tokenKinds {
+				token, err := kind.generate(cfg)
+				if err != nil {
+					t.Fatalf("generate %s token: %v", kind.name, err)
+				}
+				for _, v := range validators {
+					t.Run(v.name+"/"+kind.name, func(t *testing.T) {
+						err := v.validate(token, cfg)
+						if want := v.accepts[kind.name]; (err == nil) != want {
+							t.Errorf("accepted = %v, want %v (err: %v)", err == nil, want, err)
+						}
+					})
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTokenSigningKeys(t *testing.T) {
+	cfg := jwtConfig()
+	hs256Token, err := GenerateToken(uuid.New(), uuid.New(), uuid.New(), false, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherSecret := jwtConfig()
+	otherSecret.JWT.Secret = "other-secret"
+	if _, err := ValidateToken(hs256Token, otherSecret); err == nil {
+		t.Error("accepted a token signed with another secret")
+	}
+
+	// Once a key ring is configured the shared secret no longer signs anything
+	useTestKeyRing(t)
+	if _, err := ValidateToken(hs256Token, cfg); err == nil {
+		t.Error("accepted an HS256 token with a key ring configured")
+	}
+
+	ringToken, err := GenerateToken(uuid.New(), uuid.New(), uuid.New(), true, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ValidateToken(ringToken, cfg)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if !claims.MFA || claims.Impersonating() {
+		t.Errorf("claims = %+v, want MFA and no actor", claims)
+	}
+
+	// Another ring doesn't know the kid
+	useTestKeyRing(t)
+	if _, err := ValidateToken(ringToken, cfg); err == nil {
+		t.Error("accepted a token signed with a key that isn't in the ring")
+	}
+}
@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyManifestFile optionally schedules the keys in a key ring directory:
+//
+//	[{"kid": "2026-10", "file": "2026-10.pem", "not_before": "2026-10-01T00:00:00Z", "not_after": "2027-01-01T00:00:00Z"}]
+//
+// Without it every *.pem file is a key named after the file, active immediately.
+const keyManifestFile = "keys.json"
+
+// SigningKey is a private key in the ring
+type SigningKey struct {
+	ID        string
+	Algorithm string    // "RS256" or "EdDSA"
+	NotBefore time.Time // Not used for signing before this time, published in the JWKS ahead of it
+	NotAfter  time.Time // Zero means never retired. After it tokens signed with the key are rejected.
+	private   crypto.Signer
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == "EdDSA" {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func (k *SigningKey) retired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && !now.Before(k.NotAfter)
+}
+
+// KeyRing holds the asymmetric keys used to sign and verify JWTs.
+// The newest key whose NotBefore has passed signs new tokens, every key that isn't
+// retired still verifies, so tokens issued before a rotation stay valid until they expire.
+type KeyRing struct {
+	dir string
+
+	mu   sync.RWMutex
+	keys []*SigningKey
+}
+
+type keyManifestEntry struct {
+	KID       string    `json:"kid"`
+	File      string    `json:"file"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+// LoadKeyRing reads the private keys in dir (PKCS#1/PKCS#8 RSA or PKCS#8 Ed25519 PEM files)
+func LoadKeyRing(dir string) (*KeyRing, error) {
+	ring := &KeyRing{dir: dir}
+	if err := ring.Reload(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// Reload re-reads the directory, picking up newly added or removed keys
+func (r *KeyRing) Reload() error {
+	entries, err := r.readManifest()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*SigningKey, 0, len(entries))
+	for _, e := range entries {
+		signer, alg, err := readPrivateKey(filepath.Join(r.dir, e.File))
+		if err != nil {
+			return fmt.Errorf("key %q: %w", e.KID, err)
+		}
+		keys = append(keys, &SigningKey{
+			ID:        e.KID,
+			Algorithm: alg,
+			NotBefore: e.NotBefore,
+			NotAfter:  e.NotAfter,
+			private:   signer,
+		})
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys found in %s", r.dir)
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+	return nil
+}
+
+// StartAutoReload reloads the ring every interval so keys can be rotated without a restart
+func (r *KeyRing) StartAutoReload(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := r.Reload(); err != nil {
+				// Keep the keys we have, a half-written file shouldn't take auth down
+				log.Printf("Failed to reload JWT key ring: %v", err)
+			}
+		}
+	}()
+}
+
+// SigningKey returns the key new tokens are signed with
+func (r *KeyRing) SigningKey(now time.Time) (*SigningKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var current *SigningKey
+	for _, k := range r.keys {
+		if now.Before(k.NotBefore) || k.retired(now) {
+			continue
+		}
+		if current == nil || k.NotBefore.After(current.NotBefore) ||
+			(k.NotBefore.Equal(current.NotBefore) && k.ID > current.ID) {
+			current = k
+		}
+	}
+	if current == nil {
+		return nil, errors.New("no active signing key")
+	}
+	return current, nil
+}
+
+// VerificationKey returns the public key for kid, unless it's unknown or retired
+func (r *KeyRing) VerificationKey(kid string, now time.Time) (*SigningKey, crypto.PublicKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, k := range r.keys {
+		if k.ID == kid && !k.retired(now) {
+			return k, k.private.Public(), true
+		}
+	}
+	return nil, nil, false
+}
+
+// JSONWebKey is a public key as published on /.well-known/jwks.json
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns every key that isn't retired, including scheduled ones,
+// so verifiers already have a key cached when it starts signing.
+func (r *KeyRing) JWKS(now time.Time) JSONWebKeySet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, k := range r.keys {
+		if k.retired(now) {
+			continue
+		}
+		jwk := JSONWebKey{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+		switch pub := k.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func (r *KeyRing) readManifest() ([]keyManifestEntry, error) {
+	data, err := os.ReadFile(filepath.Join(r.dir, keyManifestFile))
+	if err == nil {
+		var entries []keyManifestEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", keyManifestFile, err)
+		}
+		for _, e := range entries {
+			if e.KID == "" || e.File == "" {
+				return nil, fmt.Errorf("invalid %s: every key needs a kid and a file", keyManifestFile)
+			}
+		}
+		return entries, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	entries := make([]keyManifestEntry, 0, len(files))
+	for _, f := range files {
+		name := filepath.Base(f)
+		entries = append(entries, keyManifestEntry{KID: strings.TrimSuffix(name, ".pem"), File: name})
+	}
+	return entries, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", errors.New("no PEM block found")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, "", fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, "", errors.New("RSA keys must be at least 2048 bits")
+		}
+		return k, "RS256", nil
+	case ed25519.PrivateKey:
+		return k, "EdDSA", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// keyRing is set at startup when JWT_KEYS_DIR is configured, otherwise tokens use HS256
+var keyRing *KeyRing
+
+// UseKeyRing switches token signing and validation to the asymmetric keys in ring
+func UseKeyRing(ring *KeyRing) {
+	keyRing = ring
+}
+
+// CurrentJWKS returns the public keys of the active key ring, empty when signing with HS256
+func CurrentJWKS() JSONWebKeySet {
+	if keyRing == nil {
+		return JSONWebKeySet{Keys: []JSONWebKey{}}
+	}
+	return keyRing.JWKS(time.Now())
+}
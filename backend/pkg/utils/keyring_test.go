@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeEd25519Key(t *testing.T, dir, file string) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, file), "PRIVATE KEY", der)
+}
+
+func writeRSAKey(t *testing.T, dir, file string, bits int) {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, file), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(private))
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeManifest(t *testing.T, dir string, entries []keyManifestEntry) {
+	t.Helper()
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, keyManifestFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// scheduledKeyRing has a retired key, the current one, a newer one that also signs
+// and a scheduled one, rotating at the start of each month of 2026
+func scheduledKeyRing(t *testing.T) *KeyRing {
+	t.Helper()
+	dir := t.TempDir()
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	entries := []keyManifestEntry{
+		{KID: "retired", File: "retired.pem", NotBefore: month(1), NotAfter: month(3)},
+		{KID: "old", File: "old.pem", NotBefore: month(2), NotAfter: month(6)},
+		{KID: "current", File: "current.pem", NotBefore: month(3)},
+		{KID: "scheduled", File: "scheduled.pem", NotBefore: month(5)},
+	}
+	for _, e := range entries {
+		writeEd25519Key(t, dir, e.File)
+	}
+	writeManifest(t, dir, entries)
+
+	ring, err := LoadKeyRing(dir)
+	if err != nil {
+		t.Fatalf("LoadKeyRing: %v", err)
+	}
+	return ring
+}
+
+func TestKeyRingSigningKey(t *testing.T) {
+	ring := scheduledKeyRing(t)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantKID string
+		wantErr bool
+	}{
+		{"before any key", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), "", true},
+		{"only the first key", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), "retired", false},
+		{"newest active key wins", time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC), "old", false},
+		{"retired key stops signing", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), "current", false},
+		{"scheduled key takes over", time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), "scheduled", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ring.SigningKey(tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SigningKey error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && key.ID != tt.wantKID {
+				t.Errorf("SigningKey = %q, want %q", key.ID, tt.wantKID)
+			}
+		})
+	}
+}
+
+func TestKeyRingSigningKeyTieBreak(t *testing.T) {
+	dir := t.TempDir()
+	writeEd25519Key(t, dir, "a.pem")
+	writeEd25519Key(t, dir, "b.pem")
+
+	ring, err := LoadKeyRing(dir)
+	if err != nil {
+		t.Fatalf("LoadKeyRing: %v", err)
+	}
+	// Without a manifest both keys are active from the start, the highest kid signs
+	key, err := ring.SigningKey(time.Now())
+	if err != nil {
+		t.Fatalf("SigningKey: %v", err)
+	}
+	if key.ID != "b" {
+		t.Errorf("SigningKey = %q, want %q", key.ID, "b")
+	}
+}
+
+func TestKeyRingVerificationKey(t *testing.T) {
+	ring := scheduledKeyRing(t)
+	now := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		kid  string
+		want bool
+	}{
+		{"retired", false},
+		{"old", true},
+		{"current", true},
+		{"scheduled", true}, // Published ahead of signing
+		{"unknown", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kid, func(t *testing.T) {
+			_, public, ok := ring.VerificationKey(tt.kid, now)
+			if ok != tt.want {
+				t.Fatalf("VerificationKey ok = %v, want %v", ok, tt.want)
+			}
+			if ok && public == nil {
+				t.Error("VerificationKey returned no public key")
+			}
+		})
+	}
+
+	var kids []string
+	for _, k := range ring.JWKS(now).Keys {
+		kids = append(kids, k.Kid)
+	}
+	if len(kids) != 3 {
+		t.Errorf("JWKS kids = %v, want the 3 keys that aren't retired", kids)
+	}
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string)
+		wantAlg string
+		wantErr bool
+	}{
+		{
+			name:    "empty directory",
+			setup:   func(t *testing.T, dir string) {},
+			wantErr: true,
+		},
+		{
+			name:    "ed25519",
+			setup:   func(t *testing.T, dir string) { writeEd25519Key(t, dir, "k.pem") },
+			wantAlg: "EdDSA",
+		},
+		{
+			name:    "rsa",
+			setup:   func(t *testing.T, dir string) { writeRSAKey(t, dir, "k.pem", 2048) },
+			wantAlg: "RS256",
+		},
+		{
+			name:    "short rsa key",
+			setup:   func(t *testing.T, dir string) { writeRSAKey(t, dir, "k.pem", 1024) },
+			wantErr: true,
+		},
+		{
+			name: "not a key",
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "k.pem"), []byte("nope"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "manifest entry without kid",
+			setup: func(t *testing.T, dir string) {
+				writeEd25519Key(t, dir, "k.pem")
+				writeManifest(t, dir, []keyManifestEntry{{File: "k.pem"}})
+			},
+			wantErr: true,
+		},
+		{
+			name: "manifest entry with a missing file",
+			setup: func(t *testing.T, dir string) {
+				writeManifest(t, dir, []keyManifestEntry{{KID: "k", File: "k.pem"}})
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+
+			ring, err := LoadKeyRing(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadKeyRing error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			key, err := ring.SigningKey(time.Now())
+			if err != nil {
+				t.Fatalf("SigningKey: %v", err)
+			}
+			if key.ID != "k" || key.Algorithm != tt.wantAlg {
+				t.Errorf("key = %s/%s, want k/%s", key.ID, key.Algorithm, tt.wantAlg)
+			}
+		})
+	}
+}
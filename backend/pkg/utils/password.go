@@ -1,13 +1,147 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	"github.com/user/go-ecommerce/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashes are self-describing: argon2id hashes use the PHC string format
+// ($argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>) and bcrypt hashes keep their $2a$/$2b$ prefix,
+// so hashes from both algorithms can live side by side in the users table.
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// argon2Params are the cost parameters encoded in an argon2id hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
 }
 
+// HashPassword hashes with the configured algorithm and cost
+func HashPassword(password string, cfg *config.Config) (string, error) {
+	if cfg.Password.Algorithm == PasswordAlgorithmBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), cfg.Password.BcryptCost)
+		return string(bytes), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	params := configuredArgon2Params(cfg)
+	key := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// CheckPasswordHash accepts argon2id and bcrypt hashes. An empty hash (OIDC-only account) never matches.
 func CheckPasswordHash(password, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2Hash(hash)
+		if err != nil {
+			return false
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(candidate, key) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// PasswordNeedsRehash reports whether a hash was made with another algorithm or other cost
+// parameters than the configured ones. Call it after a successful CheckPasswordHash.
+func PasswordNeedsRehash(hash string, cfg *config.Config) bool {
+	if cfg.Password.Algorithm == PasswordAlgorithmBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != cfg.Password.BcryptCost
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return true
+	}
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	return params != configuredArgon2Params(cfg) || len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+}
+
+func configuredArgon2Params(cfg *config.Config) argon2Params {
+	return argon2Params{
+		memory:      uint32(cfg.Password.Argon2Memory),
+		iterations:  uint32(cfg.Password.Argon2Iterations),
+		parallelism: uint8(cfg.Password.Argon2Parallelism),
+	}
+}
+
+func decodeArgon2Hash(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	if params.memory == 0 || params.iterations == 0 || params.parallelism == 0 {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	return params, salt, key, nil
+}
+
+// ValidatePasswordConfig rejects settings HashPassword can't work with, call it at startup
+func ValidatePasswordConfig(cfg *config.Config) error {
+	switch cfg.Password.Algorithm {
+	case PasswordAlgorithmArgon2id:
+		if cfg.Password.Argon2Memory < 8*cfg.Password.Argon2Parallelism || cfg.Password.Argon2Iterations < 1 ||
+			cfg.Password.Argon2Parallelism < 1 || cfg.Password.Argon2Parallelism > 255 {
+			return errors.New("invalid argon2id parameters")
+		}
+	case PasswordAlgorithmBcrypt:
+		if cfg.Password.BcryptCost < bcrypt.MinCost || cfg.Password.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("unknown password hash algorithm %q", cfg.Password.Algorithm)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/go-ecommerce/internal/config"
+)
+
+// Cheap cost parameters, the production defaults would make the tests slow
+func argon2Config() *config.Config {
+	return &config.Config{Password: config.PasswordConfig{
+		Algorithm:         PasswordAlgorithmArgon2id,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}}
+}
+
+func bcryptConfig(cost int) *config.Config {
+	return &config.Config{Password: config.PasswordConfig{
+		Algorithm:  PasswordAlgorithmBcrypt,
+		BcryptCost: cost,
+	}}
+}
+
+func mustHashPassword(t *testing.T, password string, cfg *config.Config) string {
+	t.Helper()
+	hash, err := HashPassword(password, cfg)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	return hash
+}
+
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *config.Config
+		prefix string
+	}{
+		{"argon2id", argon2Config(), "$argon2id$v=19$m=64,t=1,p=1$"},
+		{"bcrypt", bcryptConfig(4), "$2a$04$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := mustHashPassword(t, "correct horse", tt.cfg)
+			if !strings.HasPrefix(hash, tt.prefix) {
+				t.Errorf("hash = %q, want prefix %q", hash, tt.prefix)
+			}
+			if !CheckPasswordHash("correct horse", hash) {
+				t.Error("CheckPasswordHash rejected the right password")
+			}
+			if CheckPasswordHash("correct horsE", hash) {
+				t.Error("CheckPasswordHash accepted a wrong password")
+			}
+			if other := mustHashPassword(t, "correct horse", tt.cfg); other == hash {
+				t.Error("two hashes of the same password are equal, the salt isn't random")
+			}
+		})
+	}
+}
+
+func TestCheckPasswordHash(t *testing.T) {
+	argon2Hash := mustHashPassword(t, "secret", argon2Config())
+	parts := strings.Split(argon2Hash, "$")
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"argon2id", argon2Hash, true},
+		{"bcrypt", mustHashPassword(t, "secret", bcryptConfig(4)), true},
+		{"empty hash", "", false},
+		{"unknown format", "secret", false},
+		{"argon2id missing key", strings.Join(parts[:5], "$"), false},
+		{"argon2id wrong version", strings.Replace(argon2Hash, "v=19", "v=16", 1), false},
+		{"argon2id zero iterations", strings.Replace(argon2Hash, "t=1", "t=0", 1), false},
+		{"argon2id bad salt", strings.Replace(argon2Hash, parts[4], "!!", 1), false},
+		{"argon2id other params", strings.Replace(argon2Hash, "t=1", "t=2", 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPasswordHash("secret", tt.hash); got != tt.want {
+				t.Errorf("CheckPasswordHash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	argon2Hash := mustHashPassword(t, "secret", argon2Config())
+	bcryptHash := mustHashPassword(t, "secret", bcryptConfig(4))
+
+	moreMemory := argon2Config()
+	moreMemory.Password.Argon2Memory = 128
+
+	tests := []struct {
+		name string
+		hash string
+		cfg  *config.Config
+		want bool
+	}{
+		{"argon2id with the configured params", argon2Hash, argon2Config(), false},
+		{"argon2id with other params", argon2Hash, moreMemory, true},
+		{"bcrypt when argon2id is configured", bcryptHash, argon2Config(), true},
+		{"bcrypt with the configured cost", bcryptHash, bcryptConfig(4), false},
+		{"bcrypt with another cost", bcryptHash, bcryptConfig(5), true},
+		{"argon2id when bcrypt is configured", argon2Hash, bcryptConfig(4), true},
+		{"malformed argon2id", "$argon2id$v=19$", argon2Config(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PasswordNeedsRehash(tt.hash, tt.cfg); got != tt.want {
+				t.Errorf("PasswordNeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.PasswordConfig
+		wantErr bool
+	}{
+		{"argon2id", config.PasswordConfig{Algorithm: "argon2id", Argon2Memory: 65536, Argon2Iterations: 3, Argon2Parallelism: 2}, false},
+		{"argon2id too little memory", config.PasswordConfig{Algorithm: "argon2id", Argon2Memory: 8, Argon2Iterations: 3, Argon2Parallelism: 2}, true},
+		{"argon2id no iterations", config.PasswordConfig{Algorithm: "argon2id", Argon2Memory: 65536, Argon2Parallelism: 2}, true},
+		{"argon2id parallelism overflow", config.PasswordConfig{Algorithm: "argon2id", Argon2Memory: 65536, Argon2Iterations: 3, Argon2Parallelism: 256}, true},
+		{"bcrypt", config.PasswordConfig{Algorithm: "bcrypt", BcryptCost: 12}, false},
+		{"bcrypt cost too low", config.PasswordConfig{Algorithm: "bcrypt", BcryptCost: 3}, true},
+		{"bcrypt cost too high", config.PasswordConfig{Algorithm: "bcrypt", BcryptCost: 32}, true},
+		{"unknown algorithm", config.PasswordConfig{Algorithm: "md5"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordConfig(&config.Config{Password: tt.cfg})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePasswordConfig error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
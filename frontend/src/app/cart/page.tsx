@@ -16,11 +16,21 @@ interface CartItem {
     name: string;
     slug: string;
     price: number;
+    effective_price?: number; // Customer group price
     image_url: string;
     stock: number;
   };
+  variant?: {
+    title: string; // e.g. "M / Red"
+    effective_price?: number;
+    image_url: string;
+  };
 }
 
+// The variant's price when the item is one, with the customer group price applied
+const itemPrice = (item: CartItem) =>
+  item.variant?.effective_price ?? item.product.effective_price ?? item.product.price;
+
 interface Cart {
   id: string;
   items: CartItem[];
@@ -77,7 +87,7 @@ export default function CartPage() {
     }
   };
 
-  const totalPrice = cart?.items.reduce((sum, item) => sum + (item.product.price * item.quantity), 0) || 0;
+  const totalPrice = cart?.items.reduce((sum, item) => sum + (itemPrice(item) * item.quantity), 0) || 0;
 
   if (loading) return <div className="min-h-screen bg-unify-bg"><Navbar /><div className="p-8 text-center">Loading...</div></div>;
 
@@ -117,8 +127,8 @@ export default function CartPage() {
                              
                              {/* Image */}
                              <div className="w-20 h-20 bg-gray-100 rounded-lg overflow-hidden flex-shrink-0">
-                                {item.product.image_url ? (
-                                   <img src={item.product.image_url} alt={item.product.name} className="w-full h-full object-cover" />
+                                {(item.variant?.image_url || item.product.image_url) ? (
+                                   <img src={item.variant?.image_url || item.product.image_url} alt={item.product.name} className="w-full h-full object-cover" />
                                 ) : (
                                    <div className="w-full h-full flex items-center justify-center text-xs text-gray-400">No Img</div>
                                 )}
@@ -129,7 +139,8 @@ export default function CartPage() {
                                 <Link href={`/product/${item.product.slug}`} className="font-semibold text-gray-900 line-clamp-2 hover:text-unify-green">
                                    {item.product.name}
                                 </Link>
-                                <div className="mt-1 font-bold text-gray-900">Rp {item.product.price.toLocaleString('id-ID')}</div>
+                                {item.variant && <div className="text-xs text-gray-500">{item.variant.title}</div>}
+                                <div className="mt-1 font-bold text-gray-900">Rp {itemPrice(item).toLocaleString('id-ID')}</div>
                              </div>
 
                              {/* Actions */}
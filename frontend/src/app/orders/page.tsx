@@ -16,6 +16,7 @@ interface OrderItem {
   };
   quantity: number;
   price: number;
+  variant_title?: string; // e.g. "M / Red"
 }
 
 interface Order {
@@ -100,6 +101,7 @@ export default function OrderHistoryPage() {
                       <div className="flex-1">
                           <h3 className="font-bold text-gray-900 line-clamp-1">
                              {order.items && order.items.length > 0 ? order.items[0].product.name : 'Unknown Product'}
+                             {order.items[0]?.variant_title && <span className="font-normal text-gray-500"> ({order.items[0].variant_title})</span>}
                           </h3>
                           <div className="text-sm text-gray-500 mt-1">
                              {order.items.length > 1 ? `+ ${order.items.length - 1} barang lainnya` : `${order.items[0]?.quantity} barang`}
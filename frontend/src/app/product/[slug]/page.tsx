@@ -6,18 +6,37 @@ import Navbar from '@/components/layout/Navbar';
 import { Star, Share2, Heart, Minus, Plus, ShoppingCart } from 'lucide-react';
 import Link from 'next/link';
 
+interface ProductVariant {
+  id: string;
+  sku: string;
+  title: string; // e.g. "M / Red"
+  price: number | null; // null sells at the product's price
+  effective_price?: number;
+  stock: number;
+  image_url: string;
+}
+
+interface ProductImage {
+  id: string;
+  url: string;
+  alt_text: string;
+}
+
 interface Product {
   id: string;
   name: string;
   slug: string;
   description: string;
   price: number;
+  effective_price?: number; // Customer group price
   stock: number;
   image_url: string;
   category: {
     name: string;
     slug: string;
   };
+  variants?: ProductVariant[];
+  images?: ProductImage[]; // Gallery in display order, the first one is image_url
 }
 
 import { useRouter } from 'next/navigation';
@@ -35,6 +54,8 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
   const [quantity, setQuantity] = useState(1);
+  const [variantId, setVariantId] = useState<string | null>(null);
+  const [imageId, setImageId] = useState<string | null>(null);
 
   useEffect(() => {
     if (!slug) return;
@@ -43,6 +64,9 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
       try {
         const res = await api.get(`/products/slug/${slug}`);
         setProduct(res.data);
+        // Preselect the first variant that is in stock
+        const variants: ProductVariant[] = res.data.variants || [];
+        setVariantId((variants.find((v) => v.stock > 0) || variants[0])?.id ?? null);
       } catch (err) {
         setError('Product not found or backend error');
       } finally {
@@ -52,13 +76,25 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
     fetchProduct();
   }, [slug]);
 
+  // Products with variants are sold by variant, with its own price, stock and image
+  const variant = product?.variants?.find((v) => v.id === variantId);
+  const price = variant
+    ? (variant.effective_price ?? variant.price ?? product!.price)
+    : (product?.effective_price ?? product?.price ?? 0);
+  const stock = variant ? variant.stock : (product?.stock ?? 0);
+  // A picked gallery image wins until another variant is chosen
+  const image = product?.images?.find((i) => i.id === imageId);
+  const imageURL = image?.url || variant?.image_url || product?.image_url;
+
   const handleQuantityChange = (delta: number) => {
     const newQty = quantity + delta;
-    if (newQty >= 1 && newQty <= (product?.stock || 1)) {
+    if (newQty >= 1 && newQty <= (stock || 1)) {
        setQuantity(newQty);
     }
   };
 
+  const addToCart = () => api.post('/cart', { product_id: product!.id, variant_id: variant?.id, quantity });
+
   if (loading) {
      return (
         <div className="min-h-screen bg-unify-bg">
@@ -108,22 +144,29 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
             <div className="lg:col-span-4 space-y-4">
                <div className="aspect-square bg-white rounded-xl border border-gray-200 overflow-hidden relative group">
                   {/* Main Image Placeholder */}
-                  {product.image_url ? (
-                      <img src={product.image_url} alt={product.name} className="w-full h-full object-contain" />
+                  {imageURL ? (
+                      <img src={imageURL} alt={image?.alt_text || product.name} className="w-full h-full object-contain" />
                   ) : (
                       <div className="w-full h-full flex items-center justify-center bg-gray-50 text-gray-300">
                          <span className="text-4xl">No Image</span>
                       </div>
                   )}
                </div>
-               {/* Thumbnails (Mock) */}
-               <div className="flex gap-2 overflow-x-auto pb-2">
-                   {[1,2,3].map(i => (
-                     <div key={i} className={`w-16 h-16 rounded-lg border cursor-pointer ${i===1 ? 'border-unify-green ring-1 ring-unify-green' : 'border-gray-200 hover:border-gray-300'}`}>
-                         <div className="w-full h-full bg-gray-50"></div>
-                     </div>
-                   ))}
-               </div>
+               {/* Thumbnails */}
+               {product.images && product.images.length > 1 && (
+                 <div className="flex gap-2 overflow-x-auto pb-2">
+                     {product.images.map((i) => (
+                       <button
+                         key={i.id}
+                         type="button"
+                         onClick={() => setImageId(i.id)}
+                         className={`w-16 h-16 shrink-0 rounded-lg border overflow-hidden ${i.url === imageURL ? 'border-unify-green ring-1 ring-unify-green' : 'border-gray-200 hover:border-gray-300'}`}
+                       >
+                           <img src={i.url} alt={i.alt_text || product.name} className="w-full h-full object-cover" />
+                       </button>
+                     ))}
+                 </div>
+               )}
             </div>
 
             {/* Middle Column: Info */}
@@ -142,9 +185,27 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
                </div>
 
                <div className="text-3xl font-bold text-gray-900">
-                  Rp {product.price.toLocaleString('id-ID')}
+                  Rp {price.toLocaleString('id-ID')}
                </div>
 
+               {product.variants && product.variants.length > 0 && (
+                  <div className="space-y-2">
+                     <h3 className="font-bold text-gray-900 text-sm">Pilih varian: <span className="font-normal text-gray-500">{variant?.title}</span></h3>
+                     <div className="flex flex-wrap gap-2">
+                        {product.variants.map((v) => (
+                           <button
+                             key={v.id}
+                             onClick={() => { setVariantId(v.id); setImageId(null); setQuantity(1); }}
+                             disabled={v.stock === 0}
+                             className={`px-3 py-1.5 text-sm rounded-lg border disabled:opacity-40 disabled:line-through ${v.id === variantId ? 'border-unify-green text-unify-green bg-green-50' : 'border-gray-300 text-gray-700 hover:border-gray-400'}`}
+                           >
+                              {v.title}
+                           </button>
+                        ))}
+                     </div>
+                  </div>
+               )}
+
                <div className="border-t border-b border-gray-100 py-4 space-y-3">
                   <h3 className="font-bold text-unify-green">Detail Produk</h3>
                   <div className="text-sm text-gray-700 leading-relaxed whitespace-pre-wrap">
@@ -181,26 +242,26 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
                          <button 
                            onClick={() => handleQuantityChange(1)}
                            className="p-2 hover:bg-gray-50 disabled:opacity-50"
-                           disabled={quantity >= product.stock}
+                           disabled={quantity >= stock}
                          >
                             <Plus className="w-4 h-4 text-unify-green" />
                          </button>
                       </div>
                       <div className="text-sm text-gray-500">
-                         Stok Total: <span className="font-bold text-gray-900">{product.stock}</span>
+                         Stok Total: <span className="font-bold text-gray-900">{stock}</span>
                       </div>
                   </div>
 
                   <div className="flex items-center justify-between text-sm text-gray-500">
                      <span>Subtotal</span>
-                     <span className="font-bold text-lg text-gray-900">Rp {(product.price * quantity).toLocaleString('id-ID')}</span>
+                     <span className="font-bold text-lg text-gray-900">Rp {(price * quantity).toLocaleString('id-ID')}</span>
                   </div>
 
                   <div className="space-y-3">
                      <button 
                        onClick={async () => {
                           try {
-                             await api.post('/cart', { product_id: product.id, quantity });
+                             await addToCart();
                              alert('Berhasil masuk keranjang!');
                              // Optionally update cart count in navbar context
                           } catch (err: any) {
@@ -218,7 +279,7 @@ export default function ProductDetailPage({ params }: { params: Promise<{ slug:
                      <button 
                        onClick={async () => {
                           try {
-                             await api.post('/cart', { product_id: product.id, quantity });
+                             await addToCart();
                              router.push('/cart');
                           } catch (err: any) {
                              if (err.response?.status === 401) {
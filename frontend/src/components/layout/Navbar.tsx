@@ -117,7 +117,7 @@ export default function Navbar() {
                                   <p className="text-xs text-gray-500">{item.quantity} Barang</p>
                                </div>
                                <div className="font-bold text-unify-orange text-sm">
-                                  Rp{(item.product.price * item.quantity).toLocaleString('id-ID')}
+                                  Rp{((item.variant?.effective_price ?? item.product.effective_price ?? item.product.price) * item.quantity).toLocaleString('id-ID')}
                                </div>
                             </div>
                          ))}
@@ -9,6 +9,7 @@ interface ProductCardProps {
     name: string;
     slug: string;
     price: number;
+    effective_price?: number; // Customer group price
     image_url: string;
     // Add other fields if available like rating, location, etc.
     // For now we mock rating/location as they aren't in DB yet
@@ -58,7 +59,7 @@ export default function ProductCard({ product }: ProductCardProps) {
            </h3>
            
            <div className="mt-auto">
-             <div className="text-base font-bold text-gray-900">Rp {product.price.toLocaleString('id-ID')}</div>
+             <div className="text-base font-bold text-gray-900">Rp {(product.effective_price ?? product.price).toLocaleString('id-ID')}</div>
              
              {/* Mock Discount/Rating for visual fidelity */}
              <div className="flex items-center gap-1 mt-1">
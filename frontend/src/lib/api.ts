@@ -9,9 +9,26 @@ const api = axios.create({
   withCredentials: true, // Enable sending cookies
 });
 
-// Request Interceptor (Simplified - No manual token injection needed)
+// CSRF token for cookie-authenticated writes. The API runs on another origin, so its
+// csrf_token cookie can't be read here; the same value comes from GET /auth/csrf.
+const CSRF_HEADER = 'X-CSRF-Token';
+const CSRF_ERROR = 'missing or invalid CSRF token';
+const SAFE_METHODS = ['get', 'head', 'options'];
+let csrfToken: string | null = null;
+
+const fetchCsrfToken = async (): Promise<string> => {
+  const response = await api.get('/auth/csrf');
+  csrfToken = response.data.csrf_token;
+  return csrfToken as string;
+};
+
+// Request Interceptor (Auth is the cookie, only the CSRF header is added)
 api.interceptors.request.use(
-  (config) => {
+  async (config) => {
+    const method = (config.method || 'get').toLowerCase();
+    if (!SAFE_METHODS.includes(method)) {
+      config.headers.set(CSRF_HEADER, csrfToken ?? (await fetchCsrfToken()));
+    }
     return config;
   },
   (error) => {
@@ -19,13 +36,55 @@ api.interceptors.request.use(
   }
 );
 
+// Access tokens are short-lived. On a 401 the refresh_token cookie (scoped to /api/auth)
+// gets a new one, concurrent 401s wait for the same refresh.
+// A 401 from these means the credentials themselves are wrong, refreshing won't help.
+const NO_REFRESH_URLS = ['/auth/refresh', '/auth/login', '/auth/2fa/verify'];
+let refreshPromise: Promise<void> | null = null;
+
+const refreshSession = (): Promise<void> => {
+  if (!refreshPromise) {
+    refreshPromise = api
+      .post('/auth/refresh', undefined, { withCredentials: true })
+      .then(() => {
+        // The refresh also rotates the csrf_token cookie
+        csrfToken = null;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
 // Response Interceptor
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    // Logging in or out replaces the cookie, fetch the new token and retry once
+    const config = error.config;
+    if (error.response?.status === 403 && error.response.data?.error === CSRF_ERROR && config && !config._csrfRetry) {
+      config._csrfRetry = true;
+      config.headers.set(CSRF_HEADER, await fetchCsrfToken());
+      return api(config);
+    }
     if (error.response?.status === 401) {
-       // Optional: Logout user on 401
-       useAuthStore.getState().logout();
+      if (!config || NO_REFRESH_URLS.includes(config.url)) {
+        return Promise.reject(error);
+      }
+      if (!config._authRetry) {
+        config._authRetry = true;
+        try {
+          await refreshSession();
+        } catch {
+          // Refresh token expired or revoked, the session is over
+          useAuthStore.getState().logout();
+          return Promise.reject(error);
+        }
+        // Sent with the new access cookie, the request interceptor fetches a fresh CSRF token
+        return api(config);
+      }
+      useAuthStore.getState().logout();
     }
     return Promise.reject(error);
   }
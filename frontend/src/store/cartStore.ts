@@ -9,8 +9,13 @@ interface CartItem {
     name: string;
     image_url: string;
     price: number;
+    effective_price?: number; // Customer group price
     slug: string;
   };
+  variant?: {
+    title: string;
+    effective_price?: number;
+  };
   quantity: number;
   total_price: number;
 }